@@ -0,0 +1,140 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const argRest = "rest"
+
+// Arg returns the `arg` tag value, eg: "0" or "rest", and a bool indicating if the field has the `arg` tag.
+// Tag options, eg: `arg:"0,required"` are not included in the returned value.
+func (f *Field) Arg() (string, bool) {
+	arg, _ := splitTag(f.field.Tag.Get(argTag))
+	if arg != "" {
+		return arg, true
+	}
+
+	return "", false
+}
+
+// argIndex returns the index of the positional arg bound to the field, or -1 for the `arg:"rest"` tag.
+func (f *Field) argIndex() (int, error) {
+	arg, _ := f.Arg()
+	if arg == argRest {
+		return -1, nil
+	}
+
+	i, err := strconv.Atoi(arg)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid arg tag %q, expected a positional index or %q", arg, argRest)
+	}
+
+	return i, nil
+}
+
+// LoadArgs recursively scans struct fields for the `arg` tag then sets the values from positional CLI args,
+// ie: the args that remain after flags, their values and subcommands are parsed.
+// `arg:"0"` binds the first positional arg, `arg:"1"` the second, and so on.
+// `arg:"rest"` binds every positional arg after the highest bound index, eg: to a []string.
+// Fields tagged as required, eg: `arg:"0,required"`, that are not set result in a *MissingFieldsError.
+// Eg: for `mytool migrate ./dir`
+//
+//	type Config struct {
+//		Command string `arg:"0,required"`
+//		Dir     string `arg:"1" default:"."`
+//	}
+func LoadArgs(ptr any) error {
+	return LoadArgsFrom(ptr, os.Args[1:])
+}
+
+// LoadArgsFrom is like LoadArgs, but reads positional args from args instead of os.Args[1:].
+func LoadArgsFrom(ptr any, args []string) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+	fields, _ = invokedCommand(fields, args)
+
+	origins := make([][]Origin, len(fields))
+	if err := loadArgs(fields, origins, parseArgs(args, flagKinds(fields)).Positional); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, arg := f.Arg()
+		return arg
+	})
+}
+
+// loadArgs sets fields from positional args, appending to origins[i] for every field that was set.
+// The names of invoked subcommands are not bound to fields.
+func loadArgs(fields []Field, origins [][]Origin, positional []string) error {
+	names, _ := selectCommand(fields, positional)
+	positional = positional[len(names):]
+
+	// rest starts after the highest bound index
+	rest := 0
+	for _, field := range fields {
+		if _, arg := field.Arg(); !arg {
+			continue
+		}
+
+		i, err := field.argIndex()
+		if err != nil {
+			return fmt.Errorf("failed to set field %q from arg: %w", field.field.Name, err)
+		}
+		if i >= rest {
+			rest = i + 1
+		}
+	}
+
+	for i, field := range fields {
+		arg, ok := field.Arg()
+		if !ok {
+			continue
+		}
+
+		index, _ := field.argIndex()
+		if index >= len(positional) || (index < 0 && rest >= len(positional)) {
+			continue
+		}
+
+		var err error
+		if index < 0 {
+			err = field.setRest(positional[rest:])
+		} else {
+			err = field.setString(positional[index], true)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set field %q from arg: %w", field.field.Name, err)
+		}
+
+		origins[i] = append(origins[i], Origin{Source: SourceArg, Key: arg})
+	}
+
+	return nil
+}
+
+// setRest sets the field from the remaining positional args.
+// Slice fields get one element per arg, all other fields are set from the args joined by spaces.
+func (f *Field) setRest(rawVals []string) error {
+	if !f.accumulates() || f.field.Type.Kind() != reflect.Slice {
+		return f.setString(strings.Join(rawVals, " "), true)
+	}
+
+	slice := reflect.MakeSlice(f.field.Type, 0, len(rawVals))
+	for i, rawVal := range rawVals {
+		elem := tempField(f.field.Type.Elem(), f.field.Tag)
+		if err := elem.setString(rawVal, true); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		slice = reflect.Append(slice, elem.value)
+	}
+
+	f.value.Set(slice)
+	return nil
+}
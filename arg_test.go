@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadArgsFrom(t *testing.T) {
+	type Config struct {
+		Command string        `arg:"0"`
+		Dir     string        `arg:"1" default:"."`
+		Timeout time.Duration `arg:"2"`
+		Rest    []string      `arg:"rest"`
+		Verbose bool          `flag:"-v"`
+		Out     string        `flag:"-o"`
+	}
+
+	tests := []struct {
+		args []string
+		want Config
+	}{
+		{args: []string{}, want: Config{}},
+		{args: []string{"migrate"}, want: Config{Command: "migrate"}},
+		{args: []string{"-v", "migrate", "-o", "out.txt", "./dir"}, want: Config{Command: "migrate", Dir: "./dir"}},
+		{args: []string{"migrate", "./dir", "5s", "a", "b,c"}, want: Config{Command: "migrate", Dir: "./dir", Timeout: 5 * time.Second, Rest: []string{"a", "b,c"}}},
+		{args: []string{"run", "--", "-x", "1s", "-y"}, want: Config{Command: "run", Dir: "-x", Timeout: time.Second, Rest: []string{"-y"}}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			var got Config
+			if err := LoadArgsFrom(&got, tt.args); err != nil {
+				t.Fatalf("LoadArgsFrom: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got != want: %+v != %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadArgsFrom_required(t *testing.T) {
+	var cfg struct {
+		Command string `arg:"0,required"`
+		Dir     string `arg:"1,required"`
+	}
+
+	err := LoadArgsFrom(&cfg, []string{"migrate"})
+
+	var missingErr *MissingFieldsError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("expected *MissingFieldsError, got: %v", err)
+	}
+	want := "missing required configuration:\n  Dir (arg 1)"
+	if err.Error() != want {
+		t.Fatalf("got != want:\n%s\n!=\n%s", err.Error(), want)
+	}
+}
+
+func TestLoadArgsFrom_invalid(t *testing.T) {
+	var cfg struct {
+		Port int `arg:"first"`
+	}
+	if err := LoadArgsFrom(&cfg, []string{"8080"}); err == nil {
+		t.Fatal("expected an error for an invalid arg tag")
+	}
+
+	var cfg2 struct {
+		Port int `arg:"0"`
+	}
+	if err := LoadArgsFrom(&cfg2, []string{"abc"}); err == nil {
+		t.Fatal("expected an error for an invalid int")
+	}
+}
+
+func TestLoad_args(t *testing.T) {
+	type Config struct {
+		Command string `arg:"0,required"`
+		Dir     string `arg:"1" default:"."`
+		Host    string `flag:"--host" usage:"database host"`
+	}
+
+	var p Provenance
+	got, err := Load[Config](LoadCfg{Flags: true, Args: []string{"--host", "example.com", "migrate"}, Provenance: &p})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Config{Command: "migrate", Dir: ".", Host: "example.com"}
+	if got != want {
+		t.Fatalf("got != want: %+v != %+v", got, want)
+	}
+	if origin, _ := p.Origin("Command"); origin.String() != "arg 0" {
+		t.Fatalf("unexpected origin: %v", origin)
+	}
+
+	wantUsage := `Arguments:
+  <command> string   (required)
+  <dir> string       (default ".")
+
+Flags:
+  --host string   database host
+`
+	if usage := Usage(&Config{}); usage != wantUsage {
+		t.Fatalf("got != want:\n%s\n!=\n%s", usage, wantUsage)
+	}
+}
@@ -0,0 +1,235 @@
+package conf
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const argsTerminator = "--"
+
+// flagKind describes whether a flag takes a value, which determines how CLI args are tokenized.
+type flagKind int
+
+const (
+	flagUnknown flagKind = iota // takes the next arg as a value, if it is not a flag
+	flagSwitch                  // never takes the next arg as a value, eg: -v for a counter
+	flagBool                    // takes the next arg as a value only if it is a bool, eg: --debug false
+	flagValue                   // takes a value, eg: --host localhost or -ofile
+)
+
+// argFlag is a single occurrence of a flag in CLI args.
+type argFlag struct {
+	name  string
+	value string
+}
+
+// Args are CLI args tokenized into flags and positional args. See ParseArgs.
+type Args struct {
+	// Positional args are the args that are not flags or flag values, including every arg after "--".
+	Positional []string
+
+	flags []argFlag
+}
+
+// ParseArgs tokenizes CLI args, eg: os.Args[1:], using the flags declared by the `flag` tags of ptr.
+// In addition to the formats supported by GetFlag, ParseArgs supports:
+//
+//	-abc      clustered boolean flags, equivalent to -a -b -c
+//	-ofile    short flags with an attached value, equivalent to -o file
+//	--        terminates flags, all following args are positional
+//
+// Boolean fields only take the next arg as their value if it is a bool, eg: in `--debug false` false is the value,
+// but in `--debug file.txt`, file.txt is positional. Counter fields never take the next arg as their value.
+func ParseArgs(ptr any, args []string) (*Args, error) {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseArgs(args, flagKinds(fields)), nil
+}
+
+// flagKinds returns the kind of each flag declared by fields, and of the help flags.
+func flagKinds(fields []Field) map[string]flagKind {
+	kinds := make(map[string]flagKind)
+	for _, helpFlag := range helpFlags {
+		kinds[helpFlag] = flagSwitch
+	}
+
+	for _, field := range fields {
+		flagName, flag := field.FlagName()
+		if !flag {
+			continue
+		}
+
+		if field.value.Kind() == reflect.Bool {
+			kinds[flagName] = flagBool
+			kinds[negatedFlag(flagName)] = flagBool
+		} else if field.Counter() {
+			kinds[flagName] = flagSwitch
+		} else {
+			kinds[flagName] = flagValue
+		}
+	}
+
+	return kinds
+}
+
+// negatedFlag returns the negation of a boolean flag, eg: --no-debug for --debug, or -no-v for -v.
+func negatedFlag(flag string) string {
+	name := strings.TrimLeft(flag, flagPrefix)
+	return flag[:len(flag)-len(name)] + "no-" + name
+}
+
+// parseArgs tokenizes args. Flags that are not in kinds are flagUnknown.
+func parseArgs(args []string, kinds map[string]flagKind) *Args {
+	a := &Args{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == argsTerminator {
+			a.Positional = append(a.Positional, args[i+1:]...)
+			break
+		}
+
+		name, val, hasVal := strings.Cut(arg, "=")
+		if !isFlagArg(arg) && !isUndashedFlag(name, kinds) {
+			a.Positional = append(a.Positional, arg)
+			continue
+		}
+
+		// clustered or attached single-dash flags, eg: -abc or -ofile
+		if !strings.HasPrefix(arg, "--") && len(name) > 2 && kinds[name] == flagUnknown && kinds[name[:2]] != flagUnknown {
+			i = a.parseCluster(args, i, kinds)
+			continue
+		}
+
+		if hasVal {
+			a.flags = append(a.flags, argFlag{name: name, value: trimQuotes(val)})
+			continue
+		}
+
+		// the next arg is the value, unless it is a flag
+		if a.takesNext(kinds[name], args, i) {
+			a.flags = append(a.flags, argFlag{name: name, value: trimQuotes(args[i+1])})
+			i++
+			continue
+		}
+
+		a.flags = append(a.flags, argFlag{name: name})
+	}
+
+	return a
+}
+
+// parseCluster tokenizes the clustered single-dash flags in args[i], eg: -abc or -vofile.
+// It returns the index of the last arg consumed.
+func (a *Args) parseCluster(args []string, i int, kinds map[string]flagKind) int {
+	arg := args[i]
+	for j := 1; j < len(arg); j++ {
+		name := flagPrefix + arg[j:j+1]
+		rest := arg[j+1:]
+
+		switch {
+		case strings.HasPrefix(rest, "="):
+			a.flags = append(a.flags, argFlag{name: name, value: trimQuotes(rest[1:])})
+			return i
+
+		case kinds[name] == flagValue && rest != "":
+			a.flags = append(a.flags, argFlag{name: name, value: trimQuotes(rest)})
+			return i
+
+		case kinds[name] == flagValue && i+1 < len(args) && !isFlagArg(args[i+1]) && args[i+1] != argsTerminator:
+			a.flags = append(a.flags, argFlag{name: name, value: trimQuotes(args[i+1])})
+			return i + 1
+		}
+
+		a.flags = append(a.flags, argFlag{name: name})
+	}
+
+	return i
+}
+
+// isUndashedFlag returns true if name is a flag declared without dashes, eg: `flag:"db-conn"`.
+func isUndashedFlag(name string, kinds map[string]flagKind) bool {
+	_, declared := kinds[name]
+	return declared && !strings.HasPrefix(name, flagPrefix)
+}
+
+// takesNext returns true if the flag at args[i] takes the next arg as its value.
+func (a *Args) takesNext(kind flagKind, args []string, i int) bool {
+	if kind == flagSwitch || i+1 >= len(args) || isFlagArg(args[i+1]) || args[i+1] == argsTerminator {
+		return false
+	}
+
+	if kind == flagBool {
+		_, err := parseBool(args[i+1])
+		return args[i+1] != "" && err == nil
+	}
+
+	return true
+}
+
+// isFlagArg returns true if arg looks like a flag: it starts with a dash, and is not "-" or a negative number.
+func isFlagArg(arg string) bool {
+	return strings.HasPrefix(arg, flagPrefix) && arg != flagPrefix && arg != argsTerminator && !isNumber(arg)
+}
+
+// Lookup returns the value of the last occurrence of flag, and a bool indicating whether the flag was found.
+func (a *Args) Lookup(flag string) (string, bool) {
+	vals := a.LookupAll(flag)
+	if len(vals) == 0 {
+		return "", false
+	}
+
+	return vals[len(vals)-1], true
+}
+
+// LookupAll returns the values of every occurrence of flag, in order.
+// Occurrences without a value (for example boolean flags) are returned as "".
+func (a *Args) LookupAll(flag string) []string {
+	var vals []string
+	for _, f := range a.flags {
+		if f.name == flag {
+			vals = append(vals, f.value)
+		}
+	}
+
+	return vals
+}
+
+// lookupBool returns the value of the last occurrence of a boolean flag or its negation, eg: --debug or --no-debug.
+// The value of the negation is inverted, eg: --no-debug is false and --no-debug=false is true.
+func (a *Args) lookupBool(flag string) (string, bool) {
+	negated := negatedFlag(flag)
+
+	val, found := "", false
+	for _, f := range a.flags {
+		switch f.name {
+		case flag:
+			val, found = f.value, true
+		case negated:
+			val, found = f.value, true
+			if b, err := parseBool(f.value); err == nil {
+				val = strconv.FormatBool(!b)
+			}
+		}
+	}
+
+	return val, found
+}
+
+// Count returns the number of occurrences of flag, eg: 3 for -v in `-vvv`.
+func (a *Args) Count(flag string) int {
+	return len(a.LookupAll(flag))
+}
+
+// Flags returns the names of the flags that occur in the args, in order.
+func (a *Args) Flags() []string {
+	names := make([]string, 0, len(a.flags))
+	for _, f := range a.flags {
+		names = append(names, f.name)
+	}
+
+	return names
+}
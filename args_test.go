@@ -0,0 +1,102 @@
+package conf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	type Config struct {
+		All     bool   `flag:"-a"`
+		Bool    bool   `flag:"-b"`
+		Verbose int    `flag:"-v" count:"true"`
+		Output  string `flag:"-o"`
+		Host    string `flag:"--host"`
+		Long    string `flag:"-long"`
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		wantFlags      string
+		wantPositional string
+	}{
+		{
+			name:           "clustered",
+			args:           []string{"-abvv"},
+			wantFlags:      "-a=[] -b=[] -v=[ ] -o=[] --host=[] -long=[]",
+			wantPositional: "[]",
+		},
+		{
+			name:           "attached value",
+			args:           []string{"-ofile.txt", "-avofile2.txt"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[file.txt file2.txt] --host=[] -long=[]",
+			wantPositional: "[]",
+		},
+		{
+			name:           "separate value after cluster",
+			args:           []string{"-ao", "file.txt", "pos"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[file.txt] --host=[] -long=[]",
+			wantPositional: "[pos]",
+		},
+		{
+			name:           "bool does not take next arg",
+			args:           []string{"-a", "pos1", "--host", "localhost", "pos2"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[] --host=[localhost] -long=[]",
+			wantPositional: "[pos1 pos2]",
+		},
+		{
+			name:           "terminator",
+			args:           []string{"--host=x", "--", "-a", "--host=y"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[] --host=[x] -long=[]",
+			wantPositional: "[-a --host=y]",
+		},
+		{
+			name:           "single dash long flag",
+			args:           []string{"-long", "value", "-o=x"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[x] --host=[] -long=[value]",
+			wantPositional: "[]",
+		},
+		{
+			name:           "negative number value",
+			args:           []string{"--host", "-5", "-"},
+			wantFlags:      "-a=[] -b=[] -v=[] -o=[] --host=[-5] -long=[]",
+			wantPositional: "[-]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(&Config{}, tt.args)
+			if err != nil {
+				t.Fatalf("ParseArgs: %v", err)
+			}
+
+			gotFlags := ""
+			for i, flag := range []string{"-a", "-b", "-v", "-o", "--host", "-long"} {
+				if i > 0 {
+					gotFlags += " "
+				}
+				gotFlags += fmt.Sprintf("%s=%v", flag, got.LookupAll(flag))
+			}
+
+			if gotFlags != tt.wantFlags {
+				t.Errorf("flags:\ngot:  %v\nwant: %v", gotFlags, tt.wantFlags)
+			}
+			if fmt.Sprint(got.Positional) != tt.wantPositional {
+				t.Errorf("positional: got %v, want %v", got.Positional, tt.wantPositional)
+			}
+		})
+	}
+}
+
+func TestGetFlag_tokenizer(t *testing.T) {
+	args := []string{"-verbose", "--", "-v"}
+
+	if _, found := GetFlag("-v", args); found {
+		t.Errorf("-v should not match -verbose or args after --")
+	}
+
+	if _, found := GetFlag("-verbose", args); !found {
+		t.Errorf("-verbose should be found")
+	}
+}
@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"strings"
+)
+
+const cmdTag = "cmd"
+
+// Command returns the `cmd` tag value and a bool indicating if the field is a subcommand.
+// Subcommands are struct fields, whose fields are only loaded when the subcommand is invoked, eg:
+//
+//	type Config struct {
+//		Verbose bool `flag:"-v"`
+//		Migrate struct {
+//			Dir string `flag:"--dir"`
+//		} `cmd:"migrate"`
+//	}
+func (f *Field) Command() (string, bool) {
+	cmd := f.field.Tag.Get(cmdTag)
+	if cmd != "" {
+		return cmd, true
+	}
+
+	return "", false
+}
+
+// selectCommand returns the subcommands invoked by the leading positional args, eg: []string{"migrate", "up"},
+// and the paths of their fields, eg: []string{"Migrate", "Migrate.Up"}.
+func selectCommand(fields []Field, positional []string) (names, paths []string) {
+	parent := ""
+	for _, arg := range positional {
+		found := false
+		for i := range fields {
+			name, ok := fields[i].Command()
+			if !ok || name != arg || parentCommand(fields, &fields[i]) != parent {
+				continue
+			}
+
+			parent = fields[i].Path()
+			names = append(names, name)
+			paths = append(paths, parent)
+			found = true
+			break
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	return names, paths
+}
+
+// parentCommand returns the path of the closest subcommand that contains f, or "" for top level fields.
+func parentCommand(fields []Field, f *Field) string {
+	parent := ""
+	for i := range fields {
+		if _, ok := fields[i].Command(); !ok {
+			continue
+		}
+
+		path := fields[i].Path()
+		if strings.HasPrefix(f.Path(), path+".") && len(path) > len(parent) {
+			parent = path
+		}
+	}
+
+	return parent
+}
+
+// commandFields returns fields, excluding the fields of subcommands that are not in selected (by path).
+func commandFields(fields []Field, selected []string) []Field {
+	var excluded []string
+	for i := range fields {
+		if _, ok := fields[i].Command(); ok && !contains(selected, fields[i].Path()) {
+			excluded = append(excluded, fields[i].Path())
+		}
+	}
+
+	if len(excluded) == 0 {
+		return fields
+	}
+
+	var result []Field
+	for _, field := range fields {
+		if !inCommands(field.Path(), excluded) {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+// inCommands returns true if path is one of the subcommand paths, or is nested within one of them.
+func inCommands(path string, commands []string) bool {
+	for _, cmd := range commands {
+		if path == cmd || strings.HasPrefix(path, cmd+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// invokedCommand returns the subcommands invoked by args, and fields without the fields of other subcommands.
+func invokedCommand(fields []Field, args []string) ([]Field, []string) {
+	names, paths := selectCommand(fields, parseArgs(args, flagKinds(fields)).Positional)
+	return commandFields(fields, paths), names
+}
@@ -0,0 +1,135 @@
+package conf
+
+import (
+	"fmt"
+	"testing"
+)
+
+type cmdTestConfig struct {
+	Verbose bool   `flag:"-v"`
+	Host    string `env:"HOST" flag:"--host"`
+
+	Migrate struct {
+		Dir string `flag:"--dir" default:"./migrations"`
+		Up  struct {
+			Steps int `arg:"0"`
+		} `cmd:"up"`
+	} `cmd:"migrate" usage:"run database migrations"`
+
+	Serve struct {
+		Port int    `flag:"--port" default:"8080"`
+		Key  string `env:"KEY,required"`
+	} `cmd:"serve" usage:"start the server"`
+}
+
+func TestLoad_command(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantCommand string
+		wantDir     string
+		wantSteps   int
+		wantPort    int
+	}{
+		{args: []string{"-v"}, wantCommand: ""},
+		{args: []string{"migrate", "--dir", "./db"}, wantCommand: "migrate", wantDir: "./db"},
+		{args: []string{"-v", "migrate", "up", "3"}, wantCommand: "migrate up", wantDir: "./migrations", wantSteps: 3},
+		{args: []string{"serve", "--port=9090"}, wantCommand: "serve", wantPort: 9090},
+		{args: []string{"up", "3"}, wantCommand: ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			var command string
+			cfg, err := Load[cmdTestConfig](LoadCfg{
+				Env:       true,
+				Flags:     true,
+				EnvLookup: EnvMap{"HOST": "localhost", "KEY": "secret"},
+				Args:      tt.args,
+				Command:   &command,
+			})
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			if command != tt.wantCommand {
+				t.Fatalf("command: got %q, want %q", command, tt.wantCommand)
+			}
+			if cfg.Migrate.Dir != tt.wantDir || cfg.Migrate.Up.Steps != tt.wantSteps || cfg.Serve.Port != tt.wantPort {
+				t.Fatalf("unexpected config: %+v", cfg)
+			}
+			if cfg.Host != "localhost" {
+				t.Fatalf("top level fields should always be loaded, got host %q", cfg.Host)
+			}
+			if tt.wantCommand != "serve" && cfg.Serve.Key != "" {
+				t.Fatalf("fields of other commands should not be loaded, got key %q", cfg.Serve.Key)
+			}
+		})
+	}
+}
+
+func TestLoad_commandRequired(t *testing.T) {
+	_, err := Load[cmdTestConfig](LoadCfg{Env: true, Flags: true, EnvLookup: EnvMap{}, Args: []string{"serve"}})
+
+	want := "missing required configuration:\n  Serve.Key (env KEY)"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got != want: %v != %s", err, want)
+	}
+}
+
+func TestLoad_commandStrictFlags(t *testing.T) {
+	_, err := Load[cmdTestConfig](LoadCfg{Flags: true, StrictFlags: true, Args: []string{"serve", "--dir", "./db"}})
+	if _, ok := err.(*UnknownFlagsError); !ok {
+		t.Fatalf("expected *UnknownFlagsError for a flag of another command, got: %v", err)
+	}
+}
+
+func TestUsage_commands(t *testing.T) {
+	want := `Commands:
+  migrate   run database migrations
+  serve     start the server
+`
+	got := Usage(&struct {
+		Migrate struct{} `cmd:"migrate" usage:"run database migrations"`
+		Serve   struct{} `cmd:"serve" usage:"start the server"`
+	}{})
+	if got != want {
+		t.Fatalf("got != want:\n%s\n!=\n%s", got, want)
+	}
+}
+
+func TestLoad_commandStrictEnv(t *testing.T) {
+	type Config struct {
+		Host  string `env:"MYAPP_HOST"`
+		Serve struct {
+			Key string `env:"MYAPP_KEY"`
+		} `cmd:"serve"`
+	}
+
+	_, err := Load[Config](LoadCfg{
+		Env:             true,
+		Flags:           true,
+		EnvLookup:       EnvMap{"MYAPP_HOST": "localhost", "MYAPP_KEY": "secret"},
+		Args:            []string{},
+		StrictEnvPrefix: "MYAPP_",
+	})
+	if err != nil {
+		t.Fatalf("env vars of other commands should not be unknown, got: %v", err)
+	}
+}
+
+func TestLoad_commandWithoutFlags(t *testing.T) {
+	command := "unset"
+	cfg, err := Load[cmdTestConfig](LoadCfg{
+		Env:       true,
+		EnvLookup: EnvMap{"KEY": "secret"},
+		Args:      []string{"migrate"},
+		Command:   &command,
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// without flags, subcommands are not detected, and the fields of every subcommand are loaded
+	if command != "" || cfg.Serve.Key != "secret" || cfg.Migrate.Dir != "./migrations" {
+		t.Fatalf("unexpected command %q or config %+v", command, cfg)
+	}
+}
@@ -0,0 +1,209 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const oneofTag = "oneof"
+
+// Shells supported by Completion.
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+)
+
+// OneOf returns the allowed values from the space separated `oneof` tag, eg: `oneof:"debug info warn"`,
+// or nil if the field does not have the `oneof` tag.
+func (f *Field) OneOf() []string {
+	return strings.Fields(f.field.Tag.Get(oneofTag))
+}
+
+// completionFlag is a flag offered by a completion script.
+type completionFlag struct {
+	name       string
+	desc       string
+	values     []string // allowed values, eg: from the `oneof` tag
+	takesValue bool
+	repeatable bool
+}
+
+// Completion returns a completion script for the flags of ptr for shell, which is one of ShellBash, ShellZsh or ShellFish.
+// The script completes every flag with its description (from the `usage` or `desc` tag),
+// the negations of boolean flags, top level subcommands, and the allowed values of fields with the `oneof` tag.
+// The script completes the name of the running program, ie: os.Args[0].
+// Eg: to enable completion in bash
+//
+//	source <(mytool completion bash)
+func Completion(ptr any, shell string) (string, error) {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return "", err
+	}
+
+	var flags []completionFlag
+	var cmds []Field
+	for i := range fields {
+		field := &fields[i]
+		if _, cmd := field.Command(); cmd && parentCommand(fields, field) == "" {
+			cmds = append(cmds, *field)
+		}
+
+		flagName, flag := field.FlagName()
+		if !flag {
+			continue
+		}
+
+		isBool := field.value.Kind() == reflect.Bool
+		flags = append(flags, completionFlag{
+			name:       flagName,
+			desc:       field.Description(),
+			values:     field.OneOf(),
+			takesValue: !isBool && !field.Counter(),
+			repeatable: field.Counter() || field.accumulates(),
+		})
+		if isBool {
+			flags = append(flags, completionFlag{name: negatedFlag(flagName), desc: "negates " + flagName})
+		}
+	}
+
+	prog := filepath.Base(os.Args[0])
+	switch shell {
+	case ShellBash:
+		return bashCompletion(prog, flags, cmds), nil
+	case ShellZsh:
+		return zshCompletion(prog, flags, cmds), nil
+	case ShellFish:
+		return fishCompletion(prog, flags, cmds), nil
+	}
+
+	return "", fmt.Errorf("unsupported shell %q, expected %s, %s or %s", shell, ShellBash, ShellZsh, ShellFish)
+}
+
+var nonIdentRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func bashCompletion(prog string, flags []completionFlag, cmds []Field) string {
+	fn := "_" + nonIdentRegexp.ReplaceAllString(prog, "_") + "_completion"
+
+	var words []string
+	for _, cmd := range cmds {
+		name, _ := cmd.Command()
+		words = append(words, name)
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# bash completion for %s\n", prog)
+	fmt.Fprintf(sb, "%s() {\n", fn)
+	sb.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	sb.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	sb.WriteString("    case \"$prev\" in\n")
+	for _, flag := range flags {
+		words = append(words, flag.name)
+		if !flag.takesValue {
+			continue
+		}
+
+		fmt.Fprintf(sb, "        %s)\n", flag.name)
+		if len(flag.values) > 0 {
+			fmt.Fprintf(sb, "            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(flag.values, " ")))
+		} else {
+			sb.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		}
+		sb.WriteString("            return\n")
+		sb.WriteString("            ;;\n")
+	}
+	sb.WriteString("    esac\n")
+	fmt.Fprintf(sb, "    COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(words, " ")))
+	sb.WriteString("}\n")
+	fmt.Fprintf(sb, "complete -F %s %s\n", fn, prog)
+
+	return sb.String()
+}
+
+func zshCompletion(prog string, flags []completionFlag, cmds []Field) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "#compdef %s\n", prog)
+	sb.WriteString("_arguments")
+	for _, flag := range flags {
+		spec := flag.name + "[" + zshEscape(flag.desc) + "]"
+		if flag.repeatable {
+			spec = "*" + spec
+		}
+		if flag.takesValue {
+			name := strings.TrimLeft(flag.name, flagPrefix)
+			if len(flag.values) > 0 {
+				spec += ":" + name + ":(" + strings.Join(flag.values, " ") + ")"
+			} else {
+				spec += ":" + name + ":_files"
+			}
+		}
+		fmt.Fprintf(sb, " \\\n    %s", shellQuote(spec))
+	}
+
+	if len(cmds) > 0 {
+		var names []string
+		for _, cmd := range cmds {
+			name, _ := cmd.Command()
+			desc := cmd.Description()
+			names = append(names, name+`\:"`+strings.ReplaceAll(desc, `"`, `\"`)+`"`)
+		}
+		fmt.Fprintf(sb, " \\\n    %s", shellQuote("1:command:(("+strings.Join(names, " ")+"))"))
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func fishCompletion(prog string, flags []completionFlag, cmds []Field) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# fish completion for %s\n", prog)
+	for _, cmd := range cmds {
+		name, _ := cmd.Command()
+		fmt.Fprintf(sb, "complete -c %s -n __fish_use_subcommand -f -a %s", prog, shellQuote(name))
+		if desc := cmd.Description(); desc != "" {
+			fmt.Fprintf(sb, " -d %s", shellQuote(desc))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, flag := range flags {
+		fmt.Fprintf(sb, "complete -c %s", prog)
+
+		name := strings.TrimLeft(flag.name, flagPrefix)
+		switch {
+		case strings.HasPrefix(flag.name, "--"):
+			fmt.Fprintf(sb, " -l %s", shellQuote(name))
+		case len(name) == 1:
+			fmt.Fprintf(sb, " -s %s", shellQuote(name))
+		default:
+			fmt.Fprintf(sb, " -o %s", shellQuote(name))
+		}
+
+		if flag.desc != "" {
+			fmt.Fprintf(sb, " -d %s", shellQuote(flag.desc))
+		}
+		if len(flag.values) > 0 {
+			fmt.Fprintf(sb, " -x -a %s", shellQuote(strings.Join(flag.values, " ")))
+		} else if flag.takesValue {
+			sb.WriteString(" -r")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// shellQuote quotes s in single quotes for bash, zsh and fish.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape escapes the brackets in descriptions of _arguments specs.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(s)
+}
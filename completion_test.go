@@ -0,0 +1,86 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type completionTestConfig struct {
+	Host    string   `flag:"--host" usage:"host to listen on"`
+	Level   string   `flag:"--level" oneof:"debug info warn" usage:"log level"`
+	Debug   bool     `flag:"-d" usage:"it's debug mode"`
+	Verbose int      `flag:"-v" count:"true"`
+	Tags    []string `flag:"--tag"`
+	Token   string   `env:"TOKEN"`
+
+	Migrate struct {
+		Dir string `flag:"--dir"`
+	} `cmd:"migrate" usage:"run migrations"`
+}
+
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: ShellBash,
+			want: []string{
+				"        --level)\n            COMPREPLY=($(compgen -W 'debug info warn' -- \"$cur\"))\n",
+				"compgen -W 'migrate --host --level -d -no-d -v --tag --dir' -- \"$cur\"",
+				"complete -F {fn} {prog}\n",
+			},
+		},
+		{
+			shell: ShellZsh,
+			want: []string{
+				"#compdef {prog}\n",
+				"'--host[host to listen on]:host:_files'",
+				"'--level[log level]:level:(debug info warn)'",
+				`'-d[it'\''s debug mode]'`,
+				"'*-v[]'",
+				"'*--tag[]:tag:_files'",
+				`'1:command:((migrate\:"run migrations"))'`,
+			},
+		},
+		{
+			shell: ShellFish,
+			want: []string{
+				"complete -c {prog} -n __fish_use_subcommand -f -a 'migrate' -d 'run migrations'\n",
+				"complete -c {prog} -l 'host' -d 'host to listen on' -r\n",
+				"complete -c {prog} -l 'level' -d 'log level' -x -a 'debug info warn'\n",
+				"complete -c {prog} -s 'd' -d 'it'\\''s debug mode'\n",
+				"complete -c {prog} -o 'no-d' -d 'negates -d'\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got, err := Completion(&completionTestConfig{}, tt.shell)
+			if err != nil {
+				t.Fatalf("Completion: %v", err)
+			}
+
+			// the program name depends on how the tests are run, eg: conf.test
+			prog := filepath.Base(os.Args[0])
+			placeholders := strings.NewReplacer("{prog}", prog, "{fn}", "_"+nonIdentRegexp.ReplaceAllString(prog, "_")+"_completion")
+			for _, want := range tt.want {
+				want = placeholders.Replace(want)
+				if !strings.Contains(got, want) {
+					t.Errorf("completion does not contain %q:\n%s", want, got)
+				}
+			}
+			if strings.Contains(got, "TOKEN") {
+				t.Errorf("completion should only contain flags:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestCompletion_unsupportedShell(t *testing.T) {
+	if _, err := Completion(&completionTestConfig{}, "powershell"); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+}
@@ -0,0 +1,148 @@
+package conf
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+var (
+	registryMu sync.RWMutex
+	decoders   = make(map[reflect.Type]func(string) (any, error))
+	encoders   = make(map[reflect.Type]func(any) (string, error))
+)
+
+// RegisterDecoder registers a function to decode fields of type T from a string.
+// Registered decoders are used for types that do not implement encoding.TextUnmarshaler,
+// instead of decoding the value as JSON. Registering a decoder for the same type again replaces it.
+// Eg:
+//
+//	conf.RegisterDecoder(func(s string) (ByteSize, error) {
+//		return ParseByteSize(s)
+//	})
+func RegisterDecoder[T any](decode func(string) (T, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	decoders[reflect.TypeOf((*T)(nil)).Elem()] = func(s string) (any, error) {
+		return decode(s)
+	}
+}
+
+// RegisterEncoder registers a function to encode fields of type T to a string, used by Field.ExportValue.
+// Registered encoders are used for types that do not implement encoding.TextMarshaler,
+// instead of encoding the value as JSON. Registering an encoder for the same type again replaces it.
+func RegisterEncoder[T any](encode func(T) (string, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	encoders[reflect.TypeOf((*T)(nil)).Elem()] = func(v any) (string, error) {
+		return encode(v.(T))
+	}
+}
+
+func lookupDecoder(t reflect.Type) (func(string) (any, error), bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	decode, ok := decoders[t]
+	return decode, ok
+}
+
+func lookupEncoder(t reflect.Type) (func(any) (string, error), bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	encode, ok := encoders[t]
+	return encode, ok
+}
+
+// isText returns true if the field is decoded from text as a single value, rather than as JSON or a struct:
+// native types, types implementing encoding.TextUnmarshaler and types with a registered decoder.
+func (f *Field) isText() bool {
+	if f.isNative() || implementsText(f.field.Type) {
+		return true
+	}
+
+	_, ok := lookupDecoder(f.field.Type)
+	return ok
+}
+
+// implementsText returns true if t, *t or the element of pointer type t implements encoding.TextUnmarshaler.
+func implementsText(t reflect.Type) bool {
+	return t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setText sets the field from rawVal using encoding.TextUnmarshaler or a registered decoder.
+// It returns false if neither applies to the type of the field.
+func (f *Field) setText(rawVal string) (bool, error) {
+	t := f.field.Type
+
+	switch {
+	case t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType):
+		val := reflect.New(t.Elem())
+		if err := val.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(rawVal)); err != nil {
+			return true, err
+		}
+		f.value.Set(val)
+		return true, nil
+
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return true, f.value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(rawVal))
+	}
+
+	if decode, ok := lookupDecoder(t); ok {
+		val, err := decode(rawVal)
+		if err != nil {
+			return true, err
+		}
+		f.value.Set(reflect.ValueOf(val))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// exportText returns the value of the field using encoding.TextMarshaler or a registered encoder.
+// It returns false if neither applies to the type of the field.
+func (f *Field) exportText() (string, bool, error) {
+	if f.value.Kind() == reflect.Ptr && f.value.IsNil() && f.value.Type().Implements(textMarshalerType) {
+		return "", true, nil
+	}
+
+	var marshaler encoding.TextMarshaler
+	if m, ok := f.value.Interface().(encoding.TextMarshaler); ok {
+		marshaler = m
+	} else if f.value.CanAddr() {
+		if m, ok := f.value.Addr().Interface().(encoding.TextMarshaler); ok {
+			marshaler = m
+		}
+	}
+
+	if marshaler != nil {
+		buf, err := marshaler.MarshalText()
+		return string(buf), true, err
+	}
+
+	if encode, ok := lookupEncoder(f.field.Type); ok {
+		val, err := encode(f.value.Interface())
+		return val, true, err
+	}
+
+	return "", false, nil
+}
+
+// textError adds the raw value to errors from decoding text.
+func textError(err error, rawVal string) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%w, raw value: %q", err, rawVal)
+}
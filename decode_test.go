@@ -0,0 +1,127 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testRegion string
+
+func (r *testRegion) UnmarshalText(text []byte) error {
+	switch s := strings.ToLower(string(text)); s {
+	case "eu", "us":
+		*r = testRegion(s)
+		return nil
+	default:
+		return fmt.Errorf("unknown region: %q", s)
+	}
+}
+
+func (r testRegion) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(r))), nil
+}
+
+type testEndpoint struct {
+	Host string
+	Port int
+}
+
+func (e *testEndpoint) UnmarshalText(text []byte) error {
+	host, port, _ := strings.Cut(string(text), ":")
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return err
+	}
+	e.Host, e.Port = host, p
+	return nil
+}
+
+func (e testEndpoint) MarshalText() ([]byte, error) {
+	return []byte(e.Host + ":" + strconv.Itoa(e.Port)), nil
+}
+
+type testByteSize int64
+
+func init() {
+	RegisterDecoder(func(s string) (testByteSize, error) {
+		n, err := strconv.ParseInt(strings.TrimSuffix(s, "KB"), 10, 64)
+		return testByteSize(n * 1024), err
+	})
+	RegisterEncoder(func(b testByteSize) (string, error) {
+		return strconv.FormatInt(int64(b)/1024, 10) + "KB", nil
+	})
+}
+
+func TestTextEndToEnd(t *testing.T) {
+	type Config struct {
+		Region   testRegion    `env:"REGION"`
+		Endpoint testEndpoint  `env:"ENDPOINT"`
+		Backup   *testEndpoint `env:"BACKUP"`
+		Size     testByteSize  `env:"SIZE"`
+		Nested   struct {
+			Endpoint testEndpoint // not recursed into, since it implements encoding.TextUnmarshaler
+		}
+	}
+
+	env := EnvMap{
+		"REGION":   "EU",
+		"ENDPOINT": "localhost:8080",
+		"BACKUP":   "backup:9090",
+		"SIZE":     "64KB",
+	}
+
+	var cfg Config
+	if err := LoadEnvFrom(&cfg, env); err != nil {
+		t.Fatalf("LoadEnvFrom: %v", err)
+	}
+
+	if cfg.Region != "eu" {
+		t.Errorf("Region = %q, want %q", cfg.Region, "eu")
+	}
+	if cfg.Endpoint != (testEndpoint{Host: "localhost", Port: 8080}) {
+		t.Errorf("Endpoint = %+v", cfg.Endpoint)
+	}
+	if cfg.Backup == nil || *cfg.Backup != (testEndpoint{Host: "backup", Port: 9090}) {
+		t.Errorf("Backup = %+v", cfg.Backup)
+	}
+	if cfg.Size != 64*1024 {
+		t.Errorf("Size = %v, want %v", cfg.Size, 64*1024)
+	}
+
+	fields, err := FlattenStructFields(&cfg)
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+	if len(fields) != 6 {
+		t.Errorf("len(fields) = %v, want 6", len(fields))
+	}
+
+	for _, field := range fields {
+		envVar, ok := field.EnvVar()
+		if !ok {
+			continue
+		}
+
+		val, err := field.ExportValue()
+		if err != nil {
+			t.Fatalf("ExportValue(%s): %v", field.Path(), err)
+		}
+		if val != env[envVar] {
+			t.Errorf("ExportValue(%s) = %q, want %q", field.Path(), val, env[envVar])
+		}
+	}
+}
+
+func TestText_invalid(t *testing.T) {
+	type Config struct {
+		Region testRegion `env:"REGION"`
+	}
+
+	var cfg Config
+	err := LoadEnvFrom(&cfg, EnvMap{"REGION": "mars"})
+	if err == nil || !strings.Contains(err.Error(), "unknown region") {
+		t.Fatalf("expected unknown region error, got: %v", err)
+	}
+}
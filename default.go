@@ -0,0 +1,40 @@
+package conf
+
+import (
+	"fmt"
+)
+
+// LoadDefaults recursively scans struct fields for the `default` tag then sets the values from the tag.
+// Default values are decoded in the same way as the other sources, eg: JSON for numbers, base64 for []byte.
+// Eg:
+//
+//	type Config struct {
+//		Host string `default:"localhost"`
+//		Port int    `default:"8080"`
+//	}
+func LoadDefaults(ptr any) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	return loadDefaults(fields, make([][]Origin, len(fields)))
+}
+
+// loadDefaults sets fields from their default tags, appending to origins[i] for every field that was set.
+func loadDefaults(fields []Field, origins [][]Origin) error {
+	for i, field := range fields {
+		defaultVal, def := field.DefaultValue()
+		if !def {
+			continue
+		}
+
+		if err := field.setString(defaultVal, true); err != nil {
+			return fmt.Errorf("failed to set field %q from default: %w", field.field.Name, err)
+		}
+
+		origins[i] = append(origins[i], Origin{Source: SourceDefault})
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	type Config struct {
+		Host  string   `default:"localhost"`
+		Port  int      `default:"8080"`
+		Debug bool     `default:"true"`
+		Tags  []string `default:"[\"a\",\"b\"]"`
+		Data  []byte   `default:"aGVsbG8="`
+		Empty string
+		DB    struct {
+			Name string `default:"app"`
+		}
+	}
+
+	var got Config
+	if err := LoadDefaults(&got); err != nil {
+		t.Fatalf("LoadDefaults: %v", err)
+	}
+
+	want := Config{
+		Host:  "localhost",
+		Port:  8080,
+		Debug: true,
+		Tags:  []string{"a", "b"},
+		Data:  []byte("hello"),
+	}
+	want.DB.Name = "app"
+
+	wantString := fmt.Sprintf("%+v", want)
+	gotString := fmt.Sprintf("%+v", got)
+	if wantString != gotString {
+		t.Fatalf("got != want:\ngot:\n%v\nwant:\n%v", gotString, wantString)
+	}
+}
+
+func TestLoadDefaults_invalid(t *testing.T) {
+	type Config struct {
+		Port int `default:"not a number"`
+	}
+
+	var cfg Config
+	if err := LoadDefaults(&cfg); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestPrintToString_default(t *testing.T) {
+	type Config struct {
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+	}
+
+	var cfg Config
+	if err := LoadDefaults(&cfg); err != nil {
+		t.Fatalf("LoadDefaults: %v", err)
+	}
+	cfg.Port = 9090
+
+	got := PrintToString(&cfg)
+	want := `----------------------------------
+  Host   = "localhost" (default)
+  Port   = 9090
+----------------------------------
+`
+
+	if got != want {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	sepTag     = "sep"
+	defaultSep = ","
+	kvSep      = "="
+)
+
+// Sep returns the `sep` tag value used to delimit slice and map values, defaulting to ",",
+// and a bool indicating if the field has the `sep` tag.
+func (f *Field) Sep() (string, bool) {
+	if sep := f.field.Tag.Get(sepTag); sep != "" {
+		return sep, true
+	}
+
+	return defaultSep, false
+}
+
+// isDelimited returns true if the field is a slice of scalars or a map of scalars, eg: []string or map[string]int.
+func (f *Field) isDelimited() bool {
+	t := f.field.Type
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8 && isScalar(t.Elem())
+	case reflect.Map:
+		return isScalar(t.Key()) && isScalar(t.Elem())
+	}
+
+	return false
+}
+
+// isScalar returns true if values of type t are decoded from a single string, eg: numbers, strings or time.Duration.
+func isScalar(t reflect.Type) bool {
+	field := tempField(t, "")
+	if field.isText() {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// tempField returns a Field holding a new zero value of type t, used to decode and encode individual values.
+func tempField(t reflect.Type, tag reflect.StructTag) Field {
+	return Field{
+		field: reflect.StructField{Type: t, Tag: tag},
+		value: reflect.New(t).Elem(),
+	}
+}
+
+// isJSON returns true if rawVal looks like a JSON array or object.
+func isJSON(rawVal string) bool {
+	rawVal = strings.TrimSpace(rawVal)
+	return strings.HasPrefix(rawVal, "[") || strings.HasPrefix(rawVal, "{")
+}
+
+// setDelimited sets a slice field from "a,b,c" or a map field from "k1=v1,k2=v2".
+// Each value is decoded with setString, so eg: []time.Duration can be set from "1s,2s".
+func (f *Field) setDelimited(rawVal string) error {
+	sep, _ := f.Sep()
+	t := f.field.Type
+
+	var parts []string
+	if strings.TrimSpace(rawVal) != "" {
+		parts = strings.Split(rawVal, sep)
+	}
+
+	if t.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(t, 0, len(parts))
+		for i, part := range parts {
+			elem, err := f.decodeValue(t.Elem(), part)
+			if err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+			slice = reflect.Append(slice, elem)
+		}
+
+		f.value.Set(slice)
+		return nil
+	}
+
+	m := reflect.MakeMapWithSize(t, len(parts))
+	for _, part := range parts {
+		k, v, ok := strings.Cut(part, kvSep)
+		if !ok {
+			return fmt.Errorf("expected key%svalue, got %q", kvSep, part)
+		}
+
+		key, err := f.decodeValue(t.Key(), k)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+
+		val, err := f.decodeValue(t.Elem(), v)
+		if err != nil {
+			return fmt.Errorf("value of key %q: %w", k, err)
+		}
+
+		m.SetMapIndex(key, val)
+	}
+
+	f.value.Set(m)
+	return nil
+}
+
+// decodeValue decodes a single value of type t from rawVal, trimming surrounding whitespace.
+func (f *Field) decodeValue(t reflect.Type, rawVal string) (reflect.Value, error) {
+	tmp := tempField(t, f.field.Tag)
+	if err := tmp.setString(strings.TrimSpace(rawVal), true); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return tmp.value, nil
+}
+
+// exportDelimited returns the value of a slice field as "a,b,c" or of a map field as "k1=v1,k2=v2".
+// Map entries are sorted by key.
+func (f *Field) exportDelimited() (string, error) {
+	sep, _ := f.Sep()
+
+	var parts []string
+	if f.value.Kind() == reflect.Slice {
+		for i := 0; i < f.value.Len(); i++ {
+			elem, err := f.encodeValue(f.value.Index(i))
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, elem)
+		}
+
+		return strings.Join(parts, sep), nil
+	}
+
+	iter := f.value.MapRange()
+	for iter.Next() {
+		key, err := f.encodeValue(iter.Key())
+		if err != nil {
+			return "", err
+		}
+
+		val, err := f.encodeValue(iter.Value())
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, key+kvSep+val)
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, sep), nil
+}
+
+// encodeValue encodes a single value with ExportValue.
+func (f *Field) encodeValue(v reflect.Value) (string, error) {
+	tmp := tempField(v.Type(), f.field.Tag)
+	tmp.value.Set(v)
+
+	return tmp.ExportValue()
+}
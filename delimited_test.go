@@ -0,0 +1,96 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDelimited(t *testing.T) {
+	type Config struct {
+		Hosts     []string          `env:"HOSTS"`
+		Ports     []int             `env:"PORTS" sep:";"`
+		Timeouts  []time.Duration   `env:"TIMEOUTS"`
+		Labels    map[string]string `env:"LABELS"`
+		Weights   map[string]int    `env:"WEIGHTS" sep:";"`
+		JSONHosts []string          `env:"JSON_HOSTS"`
+		JSONMap   map[string]int    `env:"JSON_MAP"`
+		Empty     []string          `env:"EMPTY"`
+	}
+
+	env := EnvMap{
+		"HOSTS":      "a, b,c",
+		"PORTS":      "80;443",
+		"TIMEOUTS":   "1s,1m",
+		"LABELS":     "env=prod, team=core",
+		"WEIGHTS":    "a=1;b=2",
+		"JSON_HOSTS": `["x,y", "z"]`,
+		"JSON_MAP":   `{"a": 1}`,
+		"EMPTY":      "",
+	}
+
+	var got Config
+	if err := LoadEnvFrom(&got, env); err != nil {
+		t.Fatalf("LoadEnvFrom: %v", err)
+	}
+
+	want := Config{
+		Hosts:     []string{"a", "b", "c"},
+		Ports:     []int{80, 443},
+		Timeouts:  []time.Duration{time.Second, time.Minute},
+		Labels:    map[string]string{"env": "prod", "team": "core"},
+		Weights:   map[string]int{"a": 1, "b": 2},
+		JSONHosts: []string{"x,y", "z"},
+		JSONMap:   map[string]int{"a": 1},
+		Empty:     []string{},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got != want:\ngot:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	fields, err := FlattenStructFields(&got)
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+
+	wantExport := map[string]string{
+		"HOSTS":      `["a","b","c"]`, // no sep tag: JSON for backwards compatibility
+		"PORTS":      "80;443",
+		"TIMEOUTS":   `[1000000000,60000000000]`,
+		"LABELS":     `{"env":"prod","team":"core"}`,
+		"WEIGHTS":    "a=1;b=2",
+		"JSON_HOSTS": `["x,y","z"]`,
+		"JSON_MAP":   `{"a":1}`,
+		"EMPTY":      `[]`,
+	}
+	for _, field := range fields {
+		envVar, _ := field.EnvVar()
+		val, err := field.ExportValue()
+		if err != nil {
+			t.Fatalf("ExportValue(%s): %v", field.Path(), err)
+		}
+		if val != wantExport[envVar] {
+			t.Errorf("ExportValue(%s) = %q, want %q", field.Path(), val, wantExport[envVar])
+		}
+	}
+}
+
+func TestDelimited_invalid(t *testing.T) {
+	type Config struct {
+		Ports  []int          `env:"PORTS"`
+		Labels map[string]int `env:"LABELS"`
+	}
+
+	tests := []EnvMap{
+		{"PORTS": "80,http"},
+		{"LABELS": "a=1,b"},
+		{"LABELS": "a=x"},
+	}
+	for _, env := range tests {
+		var cfg Config
+		if err := LoadEnvFrom(&cfg, env); err == nil {
+			t.Errorf("expected an error for %v", env)
+		}
+	}
+}
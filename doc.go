@@ -1,3 +1,3 @@
 // Package conf provides a set of utilities for mapping configuration settings
-// (from env vars, flags and secret managers) to struct fields.
+// (from config files, env vars, .env files, flags and secret managers) to struct fields.
 package conf
@@ -0,0 +1,245 @@
+package conf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv reads the .env files at paths then sets the values of fields with the `env` tag.
+// Files are read in order, so values in later files override values in earlier files.
+// The process environment is not read or modified.
+// Eg:
+//
+//	type Config struct {
+//		Host string `env:"HOST"`
+//	}
+//
+// with a .env file:
+//
+//	# comments and blank lines are ignored
+//	export HOST=localhost
+func LoadDotEnv(ptr any, paths ...string) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	origins := make([][]Origin, len(fields))
+	if err := loadDotEnv(fields, origins, paths); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, env := f.EnvVar()
+		return env
+	})
+}
+
+// loadDotEnv sets fields from .env files, appending to origins[i] for every field that was set.
+func loadDotEnv(fields []Field, origins [][]Origin, paths []string) error {
+	vars := make(map[string]string)
+	files := make(map[string]string) // env var -> path of the file that set it
+	for _, path := range paths {
+		fileVars, err := readDotEnvFile(path)
+		if err != nil {
+			return err
+		}
+
+		for key, val := range fileVars {
+			vars[key] = val
+			files[key] = path
+		}
+	}
+
+	for i, field := range fields {
+		envVar, env := field.EnvVar()
+		if !env {
+			continue
+		}
+
+		envVal, ok := vars[envVar]
+		if !ok {
+			continue
+		}
+
+		if err := field.setString(envVal, true); err != nil {
+			return fmt.Errorf("failed to set field %q from .env file: %w", field.field.Name, err)
+		}
+
+		origins[i] = append(origins[i], Origin{Source: SourceDotEnv, Key: files[envVar] + ":" + envVar})
+	}
+
+	return nil
+}
+
+func readDotEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open .env file: %w", err)
+	}
+	defer f.Close()
+
+	vars, err := ParseDotEnv(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse .env file %q: %w", path, err)
+	}
+
+	return vars, nil
+}
+
+// ParseDotEnv parses the contents of a .env file. It supports the following formats:
+//
+//	# comments
+//	KEY=value
+//	KEY=value # inline comments
+//	export KEY=value
+//	KEY='single quoted value, taken literally'
+//	KEY="double quoted value, with \"escapes\"\n"
+//	KEY="double quoted values
+//	may span multiple lines"
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		// only trim the left side, so that quoted values are kept exactly, eg: trailing spaces of multi-line values
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNum)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNum, key)
+		}
+
+		val = strings.TrimLeft(val, " \t")
+		startLine := lineNum
+
+		switch {
+		case strings.HasPrefix(val, `"`):
+			// double-quoted values may span multiple lines
+			raw := val[1:]
+			end := closingQuote(raw)
+			for end < 0 && scanner.Scan() {
+				lineNum++
+				raw += "\n" + scanner.Text()
+				end = closingQuote(raw)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated double-quoted value", startLine)
+			}
+			if err := checkTrailing(raw[end+1:]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			val = unescapeDotEnv(raw[:end])
+
+		case strings.HasPrefix(val, `'`):
+			end := strings.Index(val[1:], `'`)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single-quoted value", startLine)
+			}
+			if err := checkTrailing(val[end+2:]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			val = val[1 : end+1]
+
+		default:
+			val = strings.TrimSpace(val)
+
+			// strip inline comments, which must be preceded by whitespace
+			if i := strings.Index(val, " #"); i >= 0 {
+				val = strings.TrimSpace(val[:i])
+			}
+		}
+
+		vars[key] = val
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// WriteDotEnv writes the fields with the `env` tag to w in the .env format, so that they can be loaded with LoadDotEnv.
+// Values are exported with Field.ExportValue and quoted where necessary.
+func WriteDotEnv(w io.Writer, ptr any) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		envVar, env := field.EnvVar()
+		if !env {
+			continue
+		}
+
+		envVal, err := field.ExportValue()
+		if err != nil {
+			return fmt.Errorf("failed to export field %q: %w", field.field.Name, err)
+		}
+
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", envVar, quoteDotEnv(envVal)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closingQuote returns the index of the first unescaped double quote in s, or -1.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkTrailing returns an error if s contains anything other than whitespace or a comment.
+func checkTrailing(s string) error {
+	s = strings.TrimSpace(s)
+	if s != "" && !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("unexpected characters after quoted value: %q", s)
+	}
+
+	return nil
+}
+
+var (
+	dotEnvUnescaper = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	dotEnvEscaper   = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`, `"`, `\"`, `\`, `\\`)
+)
+
+func unescapeDotEnv(s string) string {
+	return dotEnvUnescaper.Replace(s)
+}
+
+// quoteDotEnv double-quotes val if it would otherwise not be parsed back to the same value.
+func quoteDotEnv(val string) string {
+	if val != strings.TrimSpace(val) || strings.ContainsAny(val, "\n\r\t#\"'\\") {
+		return `"` + dotEnvEscaper.Replace(val) + `"`
+	}
+
+	return val
+}
@@ -0,0 +1,155 @@
+package conf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	input := `
+# a comment
+HOST=localhost
+export PORT=8080
+NAME = app # inline comment
+HASH=a#b
+EMPTY=
+SINGLE='single # quoted \n'
+DOUBLE="double \"quoted\"\tvalue"
+MULTI="first line
+second line"
+JSON=["a","b"]
+`
+
+	got, err := ParseDotEnv(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseDotEnv: %v", err)
+	}
+
+	want := map[string]string{
+		"HOST":   "localhost",
+		"PORT":   "8080",
+		"NAME":   "app",
+		"HASH":   "a#b",
+		"EMPTY":  "",
+		"SINGLE": `single # quoted \n`,
+		"DOUBLE": "double \"quoted\"\tvalue",
+		"MULTI":  "first line\nsecond line",
+		"JSON":   `["a","b"]`,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got != want:\ngot:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestParseDotEnv_quotedWhitespace(t *testing.T) {
+	got, err := ParseDotEnv(strings.NewReader("  A=\"x   \n  y  \"   # comment\nB='z  '  \n"))
+	if err != nil {
+		t.Fatalf("ParseDotEnv: %v", err)
+	}
+
+	want := map[string]string{"A": "x   \n  y  ", "B": "z  "}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got != want:\ngot:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestParseDotEnv_errors(t *testing.T) {
+	tests := []string{
+		"NO_EQUALS",
+		"=value",
+		`UNTERMINATED="value`,
+		`UNTERMINATED='value`,
+		`TRAILING="value" junk`,
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseDotEnv(strings.NewReader(input)); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestDotEnvEndToEnd(t *testing.T) {
+	type Config struct {
+		String string `env:"TEST_STRING"`
+		Int    int    `env:"TEST_INT"`
+		Bool   bool   `env:"TEST_BOOL"`
+		Struct struct {
+			String string `env:"TEST_STRUCT_STRING"`
+		}
+		Bytes []byte   `env:"TEST_BYTES"`
+		Slice []string `env:"TEST_SLICE"`
+	}
+
+	want := Config{}
+	want.String = " string with # and spaces "
+	want.Int = 42
+	want.Bool = true
+	want.Struct.String = `string ' with spaces " quotes
+		and newlines \n`
+	want.Bytes = []byte("placeholder bytes from a string")
+	want.Slice = []string{"a", "b"}
+
+	buf := bytes.NewBuffer(nil)
+	if err := WriteDotEnv(buf, &want); err != nil {
+		t.Fatalf("WriteDotEnv: %v", err)
+	}
+	t.Log(buf.String())
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	var got Config
+	if err := LoadDotEnv(&got, path); err != nil {
+		t.Fatalf("LoadDotEnv: %v", err)
+	}
+
+	wantString := fmt.Sprintf("%+v", want)
+	gotString := fmt.Sprintf("%+v", got)
+	if wantString != gotString {
+		t.Fatalf("got != want:\ngot:\n%v\nwant:\n%v", gotString, wantString)
+	}
+}
+
+func TestLoad_dotEnvPrecedence(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_DOTENV_HOST"`
+		Port int    `env:"TEST_DOTENV_PORT"`
+		Name string `env:"TEST_DOTENV_NAME"`
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	_ = os.WriteFile(base, []byte("TEST_DOTENV_HOST=base\nTEST_DOTENV_PORT=1\nTEST_DOTENV_NAME=base"), 0o600)
+	_ = os.WriteFile(local, []byte("TEST_DOTENV_PORT=2\nTEST_DOTENV_NAME=local"), 0o600)
+
+	var prov Provenance
+	got, err := Load[Config](LoadCfg{
+		Env:         true,
+		EnvLookup:   EnvMap{"TEST_DOTENV_NAME": "env"},
+		DotEnvFiles: []string{base, local},
+		Provenance:  &prov,
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Config{Host: "base", Port: 2, Name: "env"}
+	if got != want {
+		t.Fatalf("got != want: %+v != %+v", got, want)
+	}
+
+	if origin, _ := prov.Origin("Port"); origin.Source != SourceDotEnv || origin.Key != local+":TEST_DOTENV_PORT" {
+		t.Errorf("unexpected origin for Port: %v", origin)
+	}
+}
@@ -1,33 +1,121 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 )
 
+// EnvLookup looks up the values of env vars, eg: from the process environment or a map in tests.
+type EnvLookup interface {
+	// LookupEnv returns the value of the env var and a bool indicating if it was set.
+	LookupEnv(key string) (string, bool)
+}
+
+// LookupFunc adapts a lookup function, eg: os.LookupEnv, to the EnvLookup interface.
+type LookupFunc func(key string) (string, bool)
+
+func (f LookupFunc) LookupEnv(key string) (string, bool) {
+	return f(key)
+}
+
+// EnvMap is an EnvLookup backed by a map of env vars, eg: for hermetic tests.
+type EnvMap map[string]string
+
+func (m EnvMap) LookupEnv(key string) (string, bool) {
+	val, ok := m[key]
+	return val, ok
+}
+
+// keys returns the sorted names of the env vars in m.
+func (m EnvMap) keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Environ returns an EnvMap from a slice of env vars in the form KEY=value, eg: os.Environ().
+func Environ(environ []string) EnvMap {
+	m := make(EnvMap, len(environ))
+	for _, kv := range environ {
+		key, val, _ := strings.Cut(kv, "=")
+		m[key] = val
+	}
+
+	return m
+}
+
+// osEnv is the default EnvLookup, reading from the process environment.
+var osEnv = LookupFunc(os.LookupEnv)
+
+// envKeys returns the names of all env vars in env, if it is able to list them.
+func envKeys(env EnvLookup) ([]string, error) {
+	if env == nil {
+		return Environ(os.Environ()).keys(), nil
+	}
+
+	if m, ok := env.(EnvMap); ok {
+		return m.keys(), nil
+	}
+
+	return nil, errors.New("listing env vars requires the process environment or an EnvMap")
+}
+
 // LoadEnv recursively scans struct fields for the env tag then sets the values from the corresponding env var.
+// Fields tagged as required, eg: `env:"HOST,required"`, that are not set result in a *MissingFieldsError.
 // Eg:
 //
 //	type Config struct {
 //		Host string `env:"HOST"`
 //	}
 func LoadEnv(ptr any) error {
+	return LoadEnvFrom(ptr, osEnv)
+}
+
+// LoadEnvFrom is like LoadEnv, but looks up env vars from env instead of the process environment.
+// Eg:
+//
+//	err := LoadEnvFrom(&cfg, EnvMap{"HOST": "localhost"})
+func LoadEnvFrom(ptr any, env EnvLookup) error {
 	fields, err := FlattenStructFields(ptr)
 	if err != nil {
 		return err
 	}
 
-	for _, field := range fields {
-		envVar, env := field.EnvVar()
-		if !env {
+	origins := make([][]Origin, len(fields))
+	if err := loadEnv(fields, origins, env); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, env := f.EnvVar()
+		return env
+	})
+}
+
+// loadEnv sets fields from env vars, appending to origins[i] for every field that was set.
+func loadEnv(fields []Field, origins [][]Origin, env EnvLookup) error {
+	for i, field := range fields {
+		envVar, tagged := field.EnvVar()
+		if !tagged {
 			continue
 		}
 
-		envVal, found := os.LookupEnv(envVar)
+		envVal, ok := env.LookupEnv(envVar)
 
-		if err := field.setString(envVal, found); err != nil {
+		if err := field.setString(envVal, ok); err != nil {
 			return fmt.Errorf("failed to set field %q from env var: %w", field.field.Name, err)
 		}
+
+		if ok {
+			origins[i] = append(origins[i], Origin{Source: SourceEnv, Key: envVar})
+		}
 	}
 
 	return nil
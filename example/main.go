@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/fritzkeyzer/conf"
 )
@@ -19,20 +18,22 @@ type Config struct {
 }
 
 // main demonstrates various functions of the conf package
-//   - LoadEnv loads fields from environment variables
+//   - LoadEnvFrom loads fields from environment variables (LoadEnv reads the process environment)
 //   - LoadFlags loads fields from command line flags
 //   - LoadSecrets loads fields from a secret manager
 //   - Print prints the config to stdout
 func main() {
-	// for demo purposes, we set the env vars here
-	os.Setenv("HOST", "localhost")
-	os.Setenv("DB_NAME", "app")
-	os.Setenv("DB_USER", "user from env")
-	os.Setenv("DB_PASS", "pass from env")
+	// for demo purposes, we provide the env vars here instead of reading the process environment
+	env := conf.EnvMap{
+		"HOST":    "localhost",
+		"DB_NAME": "app",
+		"DB_USER": "user from env",
+		"DB_PASS": "pass from env",
+	}
 
 	var cfg Config
 
-	if err := conf.LoadEnv(&cfg); err != nil {
+	if err := conf.LoadEnvFrom(&cfg, env); err != nil {
 		panic(err)
 	}
 
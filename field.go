@@ -6,12 +6,23 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 const (
-	envTag    = "env"
-	flagTag   = "flag"
-	secretTag = "secret"
+	envTag      = "env"
+	flagTag     = "flag"
+	secretTag   = "secret"
+	defaultTag  = "default"
+	fileTag     = "file"
+	usageTag    = "usage"
+	descTag     = "desc"
+	requiredTag = "required"
+	countTag    = "count"
+	argTag      = "arg"
+
+	requiredOpt = "required"
 )
 
 // Field represents a struct field
@@ -25,7 +36,8 @@ type Field struct {
 
 // FlattenStructFields returns a flat slice of Field from recursively traversing the struct fields of v.
 //   - unexported fields are omitted
-//   - fields marked with an env, flag or secret tag are included, but their children are not
+//   - fields marked with an env, flag, secret, default, file or arg tag are included, but their children are not
+//   - the children of types that are decoded from text, eg: time.Time or encoding.TextUnmarshaler, are not included
 func FlattenStructFields(ptr any) ([]Field, error) {
 	v := reflect.ValueOf(ptr)
 	if v.Kind() != reflect.Ptr {
@@ -59,15 +71,13 @@ func flattenFields(v reflect.Value, path []string) []Field {
 
 		fields = append(fields, f)
 
-		// do not recurse into fields that have the env, flag or secret tags
-		_, env := f.EnvVar()
-		_, flag := f.FlagName()
-		_, secret := f.SecretKey()
-		if env || flag || secret {
+		// do not recurse into fields that have the env, flag, secret, default, file or arg tags
+		if f.isTagged() {
 			continue
 		}
 
-		if f.field.Type.Kind() == reflect.Struct {
+		// do not recurse into types that are decoded from text, eg: time.Time
+		if f.field.Type.Kind() == reflect.Struct && !f.isText() {
 			subFields := flattenFields(f.value, append(path, f.name))
 			fields = append(fields, subFields...)
 		}
@@ -76,9 +86,19 @@ func flattenFields(v reflect.Value, path []string) []Field {
 	return fields
 }
 
+// Path returns the full path of the field within the struct, eg: "DB.User".
+func (f *Field) Path() string {
+	if len(f.path) == 0 {
+		return f.name
+	}
+
+	return strings.Join(f.path, ".") + "." + f.name
+}
+
 // EnvVar returns the `env` tag value and a bool indicating if the field has the `env` tag.
+// Tag options, eg: `env:"HOST,required"` are not included in the returned value.
 func (f *Field) EnvVar() (string, bool) {
-	envVar := f.field.Tag.Get(envTag)
+	envVar, _ := splitTag(f.field.Tag.Get(envTag))
 	if envVar != "" {
 		return envVar, true
 	}
@@ -87,8 +107,9 @@ func (f *Field) EnvVar() (string, bool) {
 }
 
 // FlagName returns the `flag` tag value and a bool indicating if the field has the `flag` tag.
+// Tag options, eg: `flag:"--host,required"` are not included in the returned value.
 func (f *Field) FlagName() (string, bool) {
-	flagName := f.field.Tag.Get(flagTag)
+	flagName, _ := splitTag(f.field.Tag.Get(flagTag))
 	if flagName != "" {
 		return flagName, true
 	}
@@ -97,8 +118,9 @@ func (f *Field) FlagName() (string, bool) {
 }
 
 // SecretKey returns the `secret` tag value and a bool indicating if the field has the `secret` tag.
+// Tag options, eg: `secret:"db-pass,required"` are not included in the returned value.
 func (f *Field) SecretKey() (string, bool) {
-	secretKey := f.field.Tag.Get(secretTag)
+	secretKey, _ := splitTag(f.field.Tag.Get(secretTag))
 	if secretKey != "" {
 		return secretKey, true
 	}
@@ -106,15 +128,138 @@ func (f *Field) SecretKey() (string, bool) {
 	return "", false
 }
 
+// FileKey returns the `file` tag value and a bool indicating if the field has the `file` tag.
+// Tag options, eg: `file:"db.name,required"` are not included in the returned value.
+func (f *Field) FileKey() (string, bool) {
+	fileKey, _ := splitTag(f.field.Tag.Get(fileTag))
+	if fileKey != "" {
+		return fileKey, true
+	}
+
+	return "", false
+}
+
+// fileKeyPath returns the key of the field in a config file, split into segments.
+// It is the `file` tag if present, otherwise the path of the field.
+func (f *Field) fileKeyPath() []string {
+	if fileKey, ok := f.FileKey(); ok {
+		return strings.Split(fileKey, ".")
+	}
+
+	key := make([]string, 0, len(f.path)+1)
+	key = append(key, f.path...)
+	return append(key, f.name)
+}
+
+// Counter returns true if the field has the `count:"true"` tag,
+// in which case the field is set to the number of times its flag occurs, eg: -vvv sets 3.
+func (f *Field) Counter() bool {
+	counter, err := strconv.ParseBool(f.field.Tag.Get(countTag))
+	return err == nil && counter
+}
+
+// Required returns true if the field must be supplied by a source.
+// A field is required if it has the `required:"true"` tag,
+// or if any of its env, flag, secret, file or arg tags has the required option, eg: `env:"DB_CONN,required"`.
+func (f *Field) Required() bool {
+	if required, err := strconv.ParseBool(f.field.Tag.Get(requiredTag)); err == nil && required {
+		return true
+	}
+
+	for _, tag := range []string{envTag, flagTag, secretTag, fileTag, argTag} {
+		_, opts := splitTag(f.field.Tag.Get(tag))
+		for _, opt := range opts {
+			if opt == requiredOpt {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// splitTag splits a tag value into the name and its comma separated options.
+func splitTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	return parts[0], parts[1:]
+}
+
+// DefaultValue returns the `default` tag value and a bool indicating if the field has the `default` tag.
+func (f *Field) DefaultValue() (string, bool) {
+	defaultVal := f.field.Tag.Get(defaultTag)
+	if defaultVal != "" {
+		return defaultVal, true
+	}
+
+	return "", false
+}
+
+// Description returns the `usage` tag value, or the `desc` tag value if there is no `usage` tag.
+func (f *Field) Description() string {
+	if usage := f.field.Tag.Get(usageTag); usage != "" {
+		return usage
+	}
+
+	return f.field.Tag.Get(descTag)
+}
+
+// isTagged returns true if the field has any of the env, flag, secret, default or file tags.
+func (f *Field) isTagged() bool {
+	_, env := f.EnvVar()
+	_, flag := f.FlagName()
+	_, secret := f.SecretKey()
+	_, def := f.DefaultValue()
+	_, file := f.FileKey()
+	_, arg := f.Arg()
+
+	return env || flag || secret || def || file || arg
+}
+
+// isDefault returns true if the field has a `default` tag and its current value equals the decoded default.
+func (f *Field) isDefault() bool {
+	defaultVal, ok := f.DefaultValue()
+	if !ok {
+		return false
+	}
+
+	tmp := tempField(f.field.Type, f.field.Tag)
+	if err := tmp.setString(defaultVal, true); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(tmp.value.Interface(), f.value.Interface())
+}
+
 // ExportValue returns the value of the field as a string.
+//   - time.Duration, time.Time, url.URL, net.IP, net.IPNet, netip types, regexp.Regexp and slog.Level
+//     fields use their natural string representation, eg: "30s" (time.Time uses the `layout` tag)
+//   - types implementing encoding.TextMarshaler are marshalled to text
+//   - types with an encoder registered with RegisterEncoder use that encoder
 //   - []byte fields are base64 encoded
+//   - slices and maps of scalars with a `sep` tag are delimited, eg: "a,b,c" or "k1=v1,k2=v2"
 //   - string fields are not pre-processed
 //   - all other types marshalled to JSON
 func (f *Field) ExportValue() (string, error) {
+	if f.isNative() {
+		return f.exportNative()
+	}
+
+	if val, ok, err := f.exportText(); ok {
+		return val, err
+	}
+
 	if f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Uint8 {
 		return base64.StdEncoding.EncodeToString(f.value.Bytes()), nil
 	}
 
+	if _, sep := f.Sep(); sep && f.isDelimited() {
+		return f.exportDelimited()
+	}
+
 	if f.value.Kind() == reflect.String {
 		return f.value.String(), nil
 	}
@@ -127,10 +272,29 @@ func (f *Field) ExportValue() (string, error) {
 }
 
 // setString sets the underlying field value from a string.
+//   - time.Duration, time.Time, url.URL, net.IP, net.IPNet, netip types, regexp.Regexp and slog.Level
+//     fields are parsed from their natural string representation, eg: "30s" (time.Time uses the `layout` tag)
+//   - types implementing encoding.TextUnmarshaler are unmarshalled from text
+//   - types with a decoder registered with RegisterDecoder use that decoder
 //   - []byte fields are assumed to be base64 encoded
+//   - slices and maps of scalars are delimited, eg: "a,b,c" or "k1=v1,k2=v2" (see the `sep` tag),
+//     unless the value starts with [ or {, in which case it is assumed to be JSON encoded
+//   - bool fields accept the formats of strconv.ParseBool, as well as yes/no and on/off, and "" is true
 //   - string fields are not pre-processed
 //   - all other types are assumed to be JSON encoded
 func (f *Field) setString(rawVal string, found bool) error {
+	if f.isText() && !found {
+		return nil
+	}
+
+	if f.isNative() {
+		return textError(f.setNative(rawVal), rawVal)
+	}
+
+	if ok, err := f.setText(rawVal); ok {
+		return textError(err, rawVal)
+	}
+
 	if f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Uint8 {
 		if !found {
 			return nil
@@ -149,19 +313,31 @@ func (f *Field) setString(rawVal string, found bool) error {
 		return nil
 	}
 
+	if found && f.isDelimited() && !isJSON(rawVal) {
+		if err := f.setDelimited(rawVal); err != nil {
+			return fmt.Errorf("%w, raw value: %q", err, rawVal)
+		}
+
+		return nil
+	}
+
 	switch f.value.Kind() {
 	case reflect.Bool:
-		if found && rawVal == "" {
-			f.value.SetBool(true)
-		} else if found && rawVal != "" {
-			f.value.SetBool(rawVal == "true")
+		if !found {
+			return nil
+		}
+
+		b, err := parseBool(rawVal)
+		if err != nil {
+			return err
 		}
+		f.value.SetBool(b)
 
 	case reflect.String:
 		if !found {
 			return nil
 		}
-		f.value.Set(reflect.ValueOf(rawVal))
+		f.value.SetString(rawVal)
 
 	default:
 		if !found {
@@ -176,3 +352,21 @@ func (f *Field) setString(rawVal string, found bool) error {
 
 	return nil
 }
+
+// parseBool parses a bool from the formats accepted by strconv.ParseBool, as well as yes/no and on/off.
+// An empty value, eg: from a flag without a value, is true.
+func parseBool(rawVal string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(rawVal)) {
+	case "", "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(rawVal))
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value %q, expected one of true/false, 1/0, yes/no or on/off", rawVal)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,175 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileDecoders decode config files into a generic document, selected by file extension.
+var fileDecoders = map[string]func(data []byte, doc *map[string]any) error{
+	".json": func(data []byte, doc *map[string]any) error { return json.Unmarshal(data, doc) },
+	".yaml": func(data []byte, doc *map[string]any) error { return yaml.Unmarshal(data, doc) },
+	".yml":  func(data []byte, doc *map[string]any) error { return yaml.Unmarshal(data, doc) },
+	".toml": func(data []byte, doc *map[string]any) error { return toml.Unmarshal(data, doc) },
+}
+
+// LoadFiles reads JSON, YAML or TOML config files (selected by the file extension),
+// then sets the values of fields from the document keys that match the field path.
+// Keys are matched case-insensitively, nested objects map to nested structs, eg: `db.name` sets Config.DB.Name.
+// The key can be overridden with the `file` tag, eg: `file:"database.name"`.
+// Files are read in order, so values in later files override values in earlier files.
+// Eg:
+//
+//	type Config struct {
+//		Host string
+//		DB   struct {
+//			Name string
+//			User string `file:"db_user"`
+//		}
+//	}
+//
+// with a config.yaml file:
+//
+//	host: localhost
+//	db:
+//	  name: app
+//	db_user: admin
+func LoadFiles(ptr any, paths ...string) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	origins := make([][]Origin, len(fields))
+	if err := loadFiles(fields, origins, paths); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, file := f.FileKey()
+		return file
+	})
+}
+
+// loadFiles sets fields from config files, appending to origins[i] for every field that was set.
+func loadFiles(fields []Field, origins [][]Origin, paths []string) error {
+	for _, path := range paths {
+		doc, err := readFile(path)
+		if err != nil {
+			return err
+		}
+
+		for i, field := range fields {
+			key := field.fileKeyPath()
+			val, ok := lookupFileKey(doc, key)
+			// null values, eg: `host:` or `host: ~` in YAML, are not set
+			if !ok || val == nil {
+				continue
+			}
+
+			// untagged structs are set from their own fields
+			if _, isMap := val.(map[string]any); isMap && !field.isTagged() && field.value.Kind() == reflect.Struct {
+				continue
+			}
+
+			rawVal, err := fileValueString(val, &field)
+			if err != nil {
+				return fmt.Errorf("failed to set field %q from file %q: %w", field.field.Name, path, err)
+			}
+
+			if err := field.setString(rawVal, true); err != nil {
+				return fmt.Errorf("failed to set field %q from file %q: %w", field.field.Name, path, err)
+			}
+
+			origins[i] = append(origins[i], Origin{Source: SourceFile, Key: path + ":" + strings.Join(key, ".")})
+		}
+	}
+
+	return nil
+}
+
+func readFile(path string) (map[string]any, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	decode, ok := fileDecoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported config file extension %q", ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]any
+	if err := decode(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+// lookupFileKey walks the document along key, matching each segment case-insensitively.
+func lookupFileKey(doc map[string]any, key []string) (any, bool) {
+	var val any = doc
+	for _, segment := range key {
+		m, ok := val.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		val, ok = m[segment]
+		if !ok {
+			found := false
+			for k, v := range m {
+				if strings.EqualFold(k, segment) {
+					val, found = v, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		}
+	}
+
+	return val, true
+}
+
+// fileValueString converts a document value to a string suitable for Field.setString.
+// Strings are used as is, dates are formatted with the `layout` tag of time.Time fields,
+// and all other values are JSON encoded (unless the field is a string).
+func fileValueString(val any, field *Field) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case time.Time:
+		// only time.Time fields are parsed with the layout tag, eg: not *time.Time
+		if field.field.Type == reflect.TypeOf(time.Time{}) {
+			return v.Format(field.layout()), nil
+		}
+		return v.Format(time.RFC3339Nano), nil
+	}
+
+	if field.value.Kind() == reflect.String {
+		if _, isMap := val.(map[string]any); !isMap {
+			if _, isSlice := val.([]any); !isSlice {
+				return fmt.Sprint(val), nil
+			}
+		}
+	}
+
+	buf, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,189 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fileTestConfig struct {
+	Host  string
+	Port  int
+	Debug bool
+	Tags  []string
+	Data  []byte
+	DB    struct {
+		Name string
+		User string `file:"db_user"`
+		Pass string `env:"TEST_FILE_DB_PASS"`
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{
+			"host": "localhost",
+			"port": 8080,
+			"debug": true,
+			"tags": ["a", "b"],
+			"data": "aGVsbG8=",
+			"db": {"name": "app", "pass": "1337"},
+			"db_user": "admin"
+		}`,
+		"config.yaml": `
+host: localhost
+port: 8080
+debug: true
+tags: [a, b]
+data: aGVsbG8=
+DB:
+  Name: app
+  pass: "1337"
+db_user: admin
+`,
+		"config.toml": `
+host = "localhost"
+port = 8080
+debug = true
+tags = ["a", "b"]
+data = "aGVsbG8="
+db_user = "admin"
+
+[db]
+name = "app"
+pass = "1337"
+`,
+	}
+
+	want := fileTestConfig{
+		Host:  "localhost",
+		Port:  8080,
+		Debug: true,
+		Tags:  []string{"a", "b"},
+		Data:  []byte("hello"),
+	}
+	want.DB.Name = "app"
+	want.DB.User = "admin"
+	want.DB.Pass = "1337"
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+
+			var got fileTestConfig
+			if err := LoadFiles(&got, path); err != nil {
+				t.Fatalf("LoadFiles: %v", err)
+			}
+
+			wantString := fmt.Sprintf("%+v", want)
+			gotString := fmt.Sprintf("%+v", got)
+			if wantString != gotString {
+				t.Fatalf("got != want:\ngot:\n%v\nwant:\n%v", gotString, wantString)
+			}
+		})
+	}
+}
+
+func TestLoadFiles_unsupported(t *testing.T) {
+	var cfg fileTestConfig
+	if err := LoadFiles(&cfg, "config.ini"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLoad_filesMerged(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	override := filepath.Join(dir, "override.json")
+	_ = os.WriteFile(base, []byte("host: base\nport: 1\ndb:\n  name: app\n  pass: from-file"), 0o600)
+	_ = os.WriteFile(override, []byte(`{"port": 2}`), 0o600)
+
+	var prov Provenance
+	got, err := Load[fileTestConfig](LoadCfg{
+		Env:        true,
+		EnvLookup:  EnvMap{"TEST_FILE_DB_PASS": "from-env"},
+		Files:      []string{base, override},
+		Provenance: &prov,
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if got.Host != "base" || got.Port != 2 || got.DB.Name != "app" || got.DB.Pass != "from-env" {
+		t.Fatalf("unexpected config: %+v", got)
+	}
+
+	if origin, _ := prov.Origin("Port"); origin != (Origin{Source: SourceFile, Key: override + ":Port"}) {
+		t.Errorf("unexpected origin for Port: %v", origin)
+	}
+}
+
+func TestLoad_filesNull(t *testing.T) {
+	type Config struct {
+		Host string `default:"localhost"`
+		Name string `file:"name,required"`
+	}
+
+	files := map[string]string{
+		"config.yaml": "host:\nname: ~\n",
+		"config.json": `{"host": null, "name": null}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+
+			got, err := Load[Config](LoadCfg{Files: []string{path}})
+
+			var missing *MissingFieldsError
+			if !errors.As(err, &missing) {
+				t.Fatalf("expected a *MissingFieldsError, got: %v", err)
+			}
+			if got.Host != "localhost" || got.Name != "" {
+				t.Fatalf("unexpected config: %+v", got)
+			}
+		})
+	}
+}
+
+func TestLoadFiles_dateLayout(t *testing.T) {
+	type Config struct {
+		When time.Time `layout:"2006-01-02"`
+		At   *time.Time
+	}
+
+	files := map[string]string{
+		"config.yaml": "when: 2024-01-02\nat: 2024-01-02T03:04:00Z\n",
+		"config.toml": "when = 2024-01-02\nat = 2024-01-02T03:04:00Z\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile: %v", err)
+			}
+
+			var got Config
+			if err := LoadFiles(&got, path); err != nil {
+				t.Fatalf("LoadFiles: %v", err)
+			}
+
+			if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !got.When.Equal(want) {
+				t.Errorf("When = %v, want %v", got.When, want)
+			}
+			if want := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC); got.At == nil || !got.At.Equal(want) {
+				t.Errorf("At = %v, want %v", got.At, want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package conf
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 )
 
@@ -10,8 +11,10 @@ const flagPrefix = "-"
 
 // GetFlag is a utility to extract a flag from a slice of CLI args.
 // It returns the value of the flag and a boolean indicating whether the flag was found.
+// If the flag is repeated, the value of the last occurrence is returned.
+// Args after the "--" terminator are not parsed as flags.
 // For example, args could be os.Args[1:].
-// flag should include the prefix, eg: "--verbose" or "-v"
+// flag should include the prefix, eg: "--verbose" or "-v", flags without a prefix, eg: "db-conn", are matched as is.
 // GetFlag supports the following formats:
 //
 //	flag=value
@@ -20,40 +23,45 @@ const flagPrefix = "-"
 //	flag value
 //	flag "value"
 //	flag 'value'
+//
+// Since GetFlag does not know which flags are boolean, clustered (-abc) and attached (-ofile) short flags
+// are not supported, see ParseArgs for those.
 func GetFlag(flag string, args []string) (val string, found bool) {
-	for i := range args {
-		if !strings.HasPrefix(args[i], flag) {
-			continue
-		}
-
-		if strings.HasPrefix(args[i], flag+"=") {
-			val = strings.TrimPrefix(args[i], flag+"=")
-			val = strings.Trim(val, `"`)
-			val = strings.Trim(val, `'`)
-			return val, true
-		}
-
-		if args[i] == flag {
-			// if there are more args and the next arg is not a flag
-			if len(args) > i+1 && !strings.HasPrefix(args[i+1], flagPrefix) {
-				// the next arg is the value
-				val = args[i+1]
-				val = strings.Trim(val, `"`)
-				val = strings.Trim(val, `'`)
+	return parseArgs(args, getFlagKinds(flag)).Lookup(flag)
+}
 
-				return val, true
-			}
+// GetFlagAll is like GetFlag, but returns the values of every occurrence of the flag, in order.
+// Occurrences without a value (for example boolean flags) are returned as "".
+// Eg: GetFlagAll("--tag", []string{"--tag", "a", "--tag=b"}) returns []string{"a", "b"}.
+func GetFlagAll(flag string, args []string) []string {
+	return parseArgs(args, getFlagKinds(flag)).LookupAll(flag)
+}
 
-			// else return found, without any value (for example boolean flags)
-			return "", true
-		}
+// getFlagKinds returns the kinds used by GetFlag, which declare flag if it has no dashes, eg: "db-conn".
+func getFlagKinds(flag string) map[string]flagKind {
+	if strings.HasPrefix(flag, flagPrefix) {
+		return nil
 	}
 
-	// all args have been checked and the flag has not been found
-	return "", false
+	return map[string]flagKind{flag: flagUnknown}
+}
+
+func trimQuotes(val string) string {
+	val = strings.Trim(val, `"`)
+	val = strings.Trim(val, `'`)
+	return val
 }
 
 // LoadFlags recursively scans struct fields for the `flag` tag then sets the values from CLI flags.
+// Boolean flags can be negated with a no- prefix, eg: --no-debug for --debug.
+// Integer fields with the `count:"true"` tag count occurrences of their flag, eg: `-v -v` or `-vvv` sets 3.
+// Values of counter flags are parsed like bools, eg: -v=false is not counted and -v=3 is an error.
+// Repeated flags are collected into slice and map fields, eg: `--tag a --tag b,c` sets []string{"a", "b", "c"}.
+// For all other fields the last occurrence wins.
+// Positional args are bound to fields with the `arg` tag, see LoadArgs.
+// If a subcommand is invoked, the flags of other subcommands are not loaded, see Field.Command.
+// Fields tagged as required, eg: `flag:"--host,required"`, that are not set result in a *MissingFieldsError.
+// If -h or --help is passed (and not declared by a field), a *HelpError wrapping ErrHelp is returned.
 // Eg:
 //
 //	type Config struct {
@@ -61,23 +69,161 @@ func GetFlag(flag string, args []string) (val string, found bool) {
 //		Verbose bool   `flag:"-v"`
 //	}
 func LoadFlags(ptr any) error {
+	return LoadFlagsFrom(ptr, os.Args[1:])
+}
+
+// LoadFlagsFrom is like LoadFlags, but reads flags from args instead of os.Args[1:].
+func LoadFlagsFrom(ptr any, args []string) error {
 	fields, err := FlattenStructFields(ptr)
 	if err != nil {
 		return err
 	}
+	fields, _ = invokedCommand(fields, args)
+
+	if helpRequested(fields, args) {
+		return &HelpError{Usage: Usage(ptr)}
+	}
 
-	for _, field := range fields {
+	origins := make([][]Origin, len(fields))
+	if err := loadFlags(fields, origins, args); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, flag := f.FlagName()
+		_, arg := f.Arg()
+		return flag || arg
+	})
+}
+
+// loadFlags sets fields from CLI flags and positional args, appending to origins[i] for every field that was set.
+func loadFlags(fields []Field, origins [][]Origin, args []string) error {
+	parsed := parseArgs(args, flagKinds(fields))
+
+	for i, field := range fields {
 		flagName, flag := field.FlagName()
 		if !flag {
 			continue
 		}
 
-		flagVar, found := GetFlag(flagName, os.Args[1:])
+		if field.Counter() {
+			n, err := countFlag(parsed.LookupAll(flagName))
+			if err == nil {
+				n, err = loadCounter(&field, n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to set field %q from flag: %w", field.field.Name, err)
+			}
+
+			if n > 0 {
+				origins[i] = append(origins[i], Origin{Source: SourceFlag, Key: flagName})
+			}
+			continue
+		}
+
+		if field.value.Kind() == reflect.Bool {
+			flagVal, ok := parsed.lookupBool(flagName)
+			if err := field.setString(flagVal, ok); err != nil {
+				return fmt.Errorf("failed to set field %q from flag: %w", field.field.Name, err)
+			}
 
-		if err := field.setString(flagVar, found); err != nil {
+			if ok {
+				origins[i] = append(origins[i], Origin{Source: SourceFlag, Key: flagName})
+			}
+			continue
+		}
+
+		flagVals := parsed.LookupAll(flagName)
+		ok := len(flagVals) > 0
+
+		var err error
+		if ok && field.accumulates() {
+			err = field.setStrings(flagVals)
+		} else if ok {
+			// last wins for scalar fields
+			err = field.setString(flagVals[len(flagVals)-1], true)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to set field %q from flag: %w", field.field.Name, err)
 		}
+
+		if ok {
+			origins[i] = append(origins[i], Origin{Source: SourceFlag, Key: flagName})
+		}
+	}
+
+	return loadArgs(fields, origins, parsed.Positional)
+}
+
+// accumulates returns true if repeated flags are collected into the field, ie: slices (except []byte) and maps.
+func (f *Field) accumulates() bool {
+	t := f.field.Type
+	if f.isText() || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+		return false
+	}
+
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+}
+
+// setStrings sets a slice or map field from repeated values, decoding each value with setString and merging the results.
+func (f *Field) setStrings(rawVals []string) error {
+	t := f.field.Type
+
+	var merged reflect.Value
+	if t.Kind() == reflect.Map {
+		merged = reflect.MakeMap(t)
+	} else {
+		merged = reflect.MakeSlice(t, 0, len(rawVals))
+	}
+
+	for _, rawVal := range rawVals {
+		tmp := tempField(t, f.field.Tag)
+		if err := tmp.setString(rawVal, true); err != nil {
+			return err
+		}
+
+		if t.Kind() == reflect.Map {
+			iter := tmp.value.MapRange()
+			for iter.Next() {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+			}
+		} else {
+			merged = reflect.AppendSlice(merged, tmp.value)
+		}
 	}
 
+	f.value.Set(merged)
 	return nil
 }
+
+// countFlag returns the number of occurrences of a counter flag that are true.
+// Occurrences without a value count, and values are parsed like bools, eg: -v=false does not count.
+func countFlag(rawVals []string) (int, error) {
+	n := 0
+	for _, rawVal := range rawVals {
+		b, err := parseBool(rawVal)
+		if err != nil {
+			return 0, err
+		}
+		if b {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// loadCounter sets an integer field to n, the number of times its flag occurs, and returns n.
+func loadCounter(field *Field, n int) (int, error) {
+	switch field.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return 0, fmt.Errorf("count tag requires an integer field, got %s", field.field.Type)
+	}
+
+	if n > 0 {
+		field.value.SetInt(int64(n))
+	}
+
+	return n, nil
+}
@@ -28,6 +28,15 @@ func TestGetFlag(t *testing.T) {
 		wantFound bool
 	}{
 		// TODO: Add test cases.
+		{
+			name: "without dashes",
+			args: args{
+				flag: "db-conn",
+				args: []string{"nonsense", "db-conn=x"},
+			},
+			wantVal:   "x",
+			wantFound: true,
+		},
 		{
 			name: "no args",
 			args: args{
@@ -91,6 +100,15 @@ func TestGetFlag(t *testing.T) {
 			wantVal:   "abc",
 			wantFound: true,
 		},
+		{
+			name: "repeated, last wins",
+			args: args{
+				flag: "--xyz",
+				args: []string{"--xyz", "abc", "--xyz=def"},
+			},
+			wantVal:   "def",
+			wantFound: true,
+		},
 		{
 			name: "overlap",
 			args: args{
@@ -113,3 +131,176 @@ func TestGetFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFlagAll(t *testing.T) {
+	args := []string{"--tag", "a", "-v", "--tag=b", "--tag", "--tagged", "x"}
+
+	got := GetFlagAll("--tag", args)
+	want := []string{"a", "b", ""}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) || len(got) != len(want) {
+		t.Fatalf("GetFlagAll() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadFlagsFrom_repeated(t *testing.T) {
+	type Config struct {
+		Tags   []string       `flag:"--tag"`
+		Ports  []int          `flag:"-p"`
+		Labels map[string]int `flag:"--label"`
+		Host   string         `flag:"--host"`
+		Data   []byte         `flag:"--data"`
+	}
+
+	args := []string{
+		"--tag", "a", "--tag=b,c",
+		"-p", "80", "-p", "443",
+		"--label", "x=1", "--label", "y=2,x=3",
+		"--host", "first", "--host", "last",
+		"--data", "Zmlyc3Q=", "--data", "bGFzdA==",
+	}
+
+	var cfg Config
+	if err := LoadFlagsFrom(&cfg, args); err != nil {
+		t.Fatalf("LoadFlagsFrom: %v", err)
+	}
+
+	got := fmt.Sprintf("%v %v %v %v %s", cfg.Tags, cfg.Ports, cfg.Labels, cfg.Host, cfg.Data)
+	want := "[a b c] [80 443] map[x:3 y:2] last last"
+	if got != want {
+		t.Fatalf("got != want:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLoadFlagsFrom_count(t *testing.T) {
+	type Config struct {
+		Verbosity int  `flag:"-v" count:"true"`
+		Quiet     int  `flag:"--quiet" count:"true"`
+		Debug     bool `flag:"-d"`
+	}
+
+	tests := []struct {
+		args []string
+		want Config
+	}{
+		{args: []string{}, want: Config{}},
+		{args: []string{"-v"}, want: Config{Verbosity: 1}},
+		{args: []string{"-v", "-v", "--quiet"}, want: Config{Verbosity: 2, Quiet: 1}},
+		{args: []string{"-vvv", "-d"}, want: Config{Verbosity: 3, Debug: true}},
+		{args: []string{"-vv", "file.txt", "-v"}, want: Config{Verbosity: 3}},
+		{args: []string{"-vx"}, want: Config{Verbosity: 1}},
+		{args: []string{"-vdv"}, want: Config{Verbosity: 2, Debug: true}},
+		{args: []string{"-v=true", "-v=false", "-v"}, want: Config{Verbosity: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			var got Config
+			if err := LoadFlagsFrom(&got, tt.args); err != nil {
+				t.Fatalf("LoadFlagsFrom: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got != want: %+v != %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFlagsFrom_countInvalid(t *testing.T) {
+	type Config struct {
+		Verbose bool `flag:"-v" count:"true"`
+	}
+
+	var cfg Config
+	if err := LoadFlagsFrom(&cfg, []string{"-v"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	var cfg2 struct {
+		Verbosity int `flag:"-v" count:"true"`
+	}
+	if err := LoadFlagsFrom(&cfg2, []string{"-v=3"}); err == nil {
+		t.Fatal("expected an error for a counter flag with a non-boolean value")
+	}
+}
+
+func TestLoadFlagsFrom_negated(t *testing.T) {
+	type Config struct {
+		Debug   bool `flag:"--debug"`
+		Verbose bool `flag:"-v"`
+	}
+
+	tests := []struct {
+		args []string
+		want Config
+	}{
+		{args: []string{}, want: Config{Debug: true, Verbose: true}},
+		{args: []string{"--no-debug"}, want: Config{Verbose: true}},
+		{args: []string{"--debug", "--no-debug"}, want: Config{Verbose: true}},
+		{args: []string{"--no-debug", "--debug"}, want: Config{Debug: true, Verbose: true}},
+		{args: []string{"--debug=off", "-v=yes"}, want: Config{Verbose: true}},
+		{args: []string{"--debug=0", "-v", "-no-v"}, want: Config{}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			cfg := Config{Debug: true, Verbose: true}
+			if err := LoadFlagsFrom(&cfg, tt.args); err != nil {
+				t.Fatalf("LoadFlagsFrom: %v", err)
+			}
+			if cfg != tt.want {
+				t.Fatalf("got != want: %+v != %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFlagsFrom_invalidBool(t *testing.T) {
+	var cfg struct {
+		Debug bool `flag:"--debug"`
+	}
+
+	if err := LoadFlagsFrom(&cfg, []string{"--debug=maybe"}); err == nil {
+		t.Fatal("expected an error for an invalid boolean value")
+	}
+}
+
+func TestLoadFlagsFrom_boolValue(t *testing.T) {
+	type Config struct {
+		Debug bool   `flag:"--debug"`
+		Host  string `flag:"--host"`
+		File  string `arg:"0"`
+	}
+
+	tests := []struct {
+		args []string
+		want Config
+	}{
+		{args: []string{"--debug", "false", "--host", "x"}, want: Config{Host: "x"}},
+		{args: []string{"--debug", "true", "--host", "x"}, want: Config{Debug: true, Host: "x"}},
+		{args: []string{"--debug", "false", "file.txt"}, want: Config{File: "file.txt"}},
+		{args: []string{"--debug", "file.txt"}, want: Config{Debug: true, File: "file.txt"}},
+		{args: []string{"--no-debug", "false"}, want: Config{Debug: true}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args), func(t *testing.T) {
+			var got Config
+			if err := LoadFlagsFrom(&got, tt.args); err != nil {
+				t.Fatalf("LoadFlagsFrom: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got != want: %+v != %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFlagsFrom_withoutDashes(t *testing.T) {
+	var cfg struct {
+		DBConn string `flag:"db-conn"`
+	}
+
+	if err := LoadFlagsFrom(&cfg, []string{"db-conn=x"}); err != nil {
+		t.Fatalf("LoadFlagsFrom: %v", err)
+	}
+	if cfg.DBConn != "x" {
+		t.Fatalf("got %q, want %q", cfg.DBConn, "x")
+	}
+}
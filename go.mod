@@ -1,7 +1,11 @@
 module github.com/fritzkeyzer/conf
 
-go 1.20
+go 1.21
 
-require github.com/olekukonko/tablewriter v0.0.5
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/olekukonko/tablewriter v0.0.5
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require github.com/mattn/go-runewidth v0.0.9 // indirect
@@ -1,44 +1,220 @@
 package conf
 
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
 type LoadCfg struct {
 	Env           bool
 	Flags         bool
 	SecretsLoader SecretsLoader
+
+	// SecretsLoaderContext, if not nil, is used like SecretsLoader, with Context.
+	SecretsLoaderContext SecretsLoaderContext
+
+	// SecretsWorkers is the number of secrets loaded concurrently from SecretsLoader, SecretsLoaderContext
+	// or a SecretSource in Sources, defaulting to DefaultSecretsWorkers (one at a time).
+	// If greater than 1, the secrets loader must be safe for concurrent use.
+	// It has no effect on a BatchSecretsLoader, which loads all secrets in one request.
+	SecretsWorkers int
+
+	// Context, if not nil, limits the time spent loading sources, eg: with context.WithTimeout.
+	// Secrets are loaded with Context, see SecretsLoaderContext, and if Context is done Load returns an error wrapping Context.Err().
+	Context context.Context
+
+	// EnvLookup, if not nil, is used to look up env vars instead of the process environment, eg: EnvMap in tests.
+	EnvLookup EnvLookup
+
+	// Args, if not nil, are parsed for flags instead of os.Args[1:].
+	Args []string
+
+	// StrictEnvPrefix, if not empty (and Env is true), returns an *UnknownEnvError
+	// if any env var starting with the prefix is not declared by a field, eg: "MYAPP_".
+	StrictEnvPrefix string
+
+	// StrictFlags, if true (and Flags is true), returns an *UnknownFlagsError if args contain undeclared flags.
+	StrictFlags bool
+
+	// Files are JSON, YAML or TOML config files to load, in order, before all other sources. See LoadFiles.
+	Files []string
+
+	// DotEnvFiles are .env files to load, in order, before env vars. See LoadDotEnv.
+	DotEnvFiles []string
+
+	// Command, if not nil, is set to the invoked subcommand, eg: "migrate" or "migrate up", or "" if none was invoked.
+	// Only the fields of the invoked subcommand are loaded, see Field.Command.
+	// Subcommands are only detected when flags are loaded, otherwise the fields of every subcommand are loaded.
+	Command *string
+
+	// Sources, if not nil, are loaded in order after defaults, instead of the sources configured by
+	// Files, SecretsLoader, DotEnvFiles, Env and Flags, eg: to load env vars before secrets, or to add custom sources.
+	// Help and strict flags are enabled by a FlagSource, and strict env by an EnvSource.
+	// Eg: []conf.Source{conf.FileSource("config.yaml"), conf.EnvSource(nil), conf.SecretSource(sm), conf.FlagSource(os.Args[1:])}
+	Sources []Source
+
+	// Provenance, if not nil, is set to a record of which sources supplied the value of each field.
+	Provenance *Provenance
 }
 
 // Load config from multiple sources.
 // T should be a struct with tagged fields:
+//   - defaults: `default:myDefaultValue`
+//   - config files: matched by field path, or `file:my.key`
 //   - secrets: `secret:mySecretValue`
-//   - env vars: `env:MY_ENV_VAR`
+//   - env vars and .env files: `env:MY_ENV_VAR`
 //   - CLI flags: `flag:--flag`
+//   - positional CLI args: `arg:0` or `arg:rest`
+//   - subcommands: `cmd:migrate` on a struct field, of which only the invoked one is loaded
 //
 // Sources are loaded in the following order:
-//  1. First load secrets from SecretsLoader (if not nil)
-//  2. Then environment variables - which will override secrets
-//  3. Finally command line flags - which override both secrets and env vars
+//  1. First apply values from default tags
+//  2. Then config files from Files (in order) - which will override defaults
+//  3. Then load secrets from SecretsLoader (if not nil) - which will override config files
+//  4. Then .env files from DotEnvFiles (in order) - which will override secrets
+//  5. Then environment variables - which will override secrets and .env files
+//  6. Finally command line flags and positional args - which override all other sources
+//
+// The order of sources after defaults can be customised with LoadCfg.Sources, eg: to add a custom Source.
+//
+// Required fields that were not supplied by any of the sources result in a *MissingFieldsError.
+// Default values do not satisfy required fields.
+// Finally the config is validated, see Validate. Violations result in a *ValidationError.
+// If Flags is true and -h or --help is passed, a *HelpError wrapping ErrHelp is returned.
 func Load[T any](cfg LoadCfg) (T, error) {
 	var v T
-	if cfg.SecretsLoader != nil {
-		err := LoadSecrets(&v, cfg.SecretsLoader)
+	fields, err := FlattenStructFields(&v)
+	if err != nil {
+		return v, err
+	}
+
+	sources := cfg.sources()
+	declared := fields
+
+	// subcommands are invoked by args, so they are only detected when flags are loaded
+	var args, command []string
+	flags, hasFlags := findSource[flagSource](sources)
+	if hasFlags {
+		args = flags.args
+		fields, command = invokedCommand(fields, args)
+	}
+	if cfg.Command != nil {
+		*cfg.Command = strings.Join(command, " ")
+	}
+
+	if hasFlags && helpRequested(fields, args) {
+		return v, &HelpError{Usage: Usage(&v)}
+	}
+
+	if env, hasEnv := findSource[envSource](sources); hasEnv && cfg.StrictEnvPrefix != "" {
+		keys, err := envKeys(env.env)
 		if err != nil {
+			return v, fmt.Errorf("strict env: %w", err)
+		}
+
+		// env vars of subcommands that were not invoked are still declared
+		if err := checkUnknownEnv(declared, keys, cfg.StrictEnvPrefix); err != nil {
 			return v, err
 		}
 	}
-	if cfg.Env {
-		err := LoadEnv(&v)
-		if err != nil {
+
+	if hasFlags && cfg.StrictFlags {
+		if err := checkUnknownFlags(fields, args); err != nil {
 			return v, err
 		}
 	}
-	if cfg.Flags {
-		err := LoadFlags(&v)
-		if err != nil {
+
+	origins := make([][]Origin, len(fields))
+	if err := loadDefaults(fields, origins); err != nil {
+		return v, err
+	}
+
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for _, source := range sources {
+		if err := loadSource(ctx, fields, origins, source); err != nil {
 			return v, err
 		}
 	}
+
+	if cfg.Provenance != nil {
+		*cfg.Provenance = newProvenance(fields, origins)
+	}
+
+	if err := checkRequired(fields, origins, allFields); err != nil {
+		return v, err
+	}
+
+	if err := validate(&v, fields, origins); err != nil {
+		return v, err
+	}
+
 	return v, nil
 }
 
+// sources returns cfg.Sources, or if nil, the sources configured by Files, SecretsLoader, DotEnvFiles, Env and Flags.
+func (cfg LoadCfg) sources() []Source {
+	if cfg.Sources != nil {
+		if cfg.SecretsWorkers < 1 {
+			return cfg.Sources
+		}
+
+		// apply SecretsWorkers to secret sources, without modifying cfg.Sources
+		sources := make([]Source, len(cfg.Sources))
+		for i, source := range cfg.Sources {
+			if s, ok := source.(secretSource); ok {
+				s.workers = cfg.SecretsWorkers
+				source = s
+			}
+			sources[i] = source
+		}
+		return sources
+	}
+
+	var sources []Source
+	if len(cfg.Files) > 0 {
+		sources = append(sources, FileSource(cfg.Files...))
+	}
+	if cfg.SecretsLoader != nil {
+		sources = append(sources, secretSource{loader: AdaptSecretsLoader(cfg.SecretsLoader), workers: cfg.SecretsWorkers})
+	}
+	if cfg.SecretsLoaderContext != nil {
+		sources = append(sources, secretSource{loader: cfg.SecretsLoaderContext, workers: cfg.SecretsWorkers})
+	}
+	if len(cfg.DotEnvFiles) > 0 {
+		sources = append(sources, DotEnvSource(cfg.DotEnvFiles...))
+	}
+	if cfg.Env {
+		sources = append(sources, EnvSource(cfg.EnvLookup))
+	}
+	if cfg.Flags {
+		args := cfg.Args
+		if args == nil {
+			args = os.Args[1:]
+		}
+		sources = append(sources, FlagSource(args))
+	}
+
+	return sources
+}
+
+// findSource returns the first source of type S.
+func findSource[S Source](sources []Source) (S, bool) {
+	for _, source := range sources {
+		if s, ok := source.(S); ok {
+			return s, true
+		}
+	}
+
+	var zero S
+	return zero, false
+}
+
 // MustLoad is a wrapper for Load which will panic if Load returns an error.
 func MustLoad[T any](cfg LoadCfg) T {
 	v, err := Load[T](cfg)
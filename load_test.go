@@ -1,7 +1,7 @@
 package conf_test
 
 import (
-	"os"
+	"fmt"
 	"strings"
 
 	"github.com/fritzkeyzer/conf"
@@ -18,13 +18,19 @@ func ExampleMustLoad() {
 	}
 
 	// fake env vars and cli flags
-	_ = os.Setenv("HOST", "localhost:1111")
-	_ = os.Setenv("DB_USER", "not_the_root_user")
-	fakeCliFlags := " --debug --host=localhost:8888" // notice how the flag here will override the env var
-	os.Args = strings.Split(fakeCliFlags, " ")
+	fakeEnv := conf.EnvMap{
+		"HOST":    "localhost:1111",
+		"DB_USER": "not_the_root_user",
+	}
+	fakeCliFlags := "--debug --host=localhost:8888" // notice how the flag here will override the env var
 
 	// one-liner loads the config
-	cfg := conf.MustLoad[Cfg](conf.LoadCfg{Env: true, Flags: true})
+	cfg := conf.MustLoad[Cfg](conf.LoadCfg{
+		Env:       true,
+		Flags:     true,
+		EnvLookup: fakeEnv,
+		Args:      strings.Split(fakeCliFlags, " "),
+	})
 
 	conf.Print(cfg)
 	//Output:
@@ -36,3 +42,16 @@ func ExampleMustLoad() {
 	//     .Pass   *** (len=0)
 	// -----------------------------------
 }
+
+func ExampleLoadEnvFrom() {
+	type Cfg struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	var cfg Cfg
+	_ = conf.LoadEnvFrom(&cfg, conf.Environ([]string{"HOST=localhost", "PORT=8080"}))
+
+	fmt.Printf("%+v", cfg)
+	// Output: {Host:localhost Port:8080}
+}
@@ -0,0 +1,195 @@
+package conf
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	layoutTag     = "layout"
+	defaultLayout = time.RFC3339Nano
+)
+
+// nativeTypes have a natural string representation, which is used instead of JSON.
+var nativeTypes = map[reflect.Type]bool{
+	reflect.TypeOf(time.Duration(0)): true,
+	reflect.TypeOf(time.Time{}):      true,
+	reflect.TypeOf(url.URL{}):        true,
+	reflect.TypeOf(&url.URL{}):       true,
+	reflect.TypeOf(net.IP{}):         true,
+	reflect.TypeOf(net.IPNet{}):      true,
+	reflect.TypeOf(&net.IPNet{}):     true,
+	reflect.TypeOf(netip.Addr{}):     true,
+	reflect.TypeOf(netip.Prefix{}):   true,
+	reflect.TypeOf(netip.AddrPort{}): true,
+	reflect.TypeOf(&regexp.Regexp{}): true,
+	reflect.TypeOf(slog.Level(0)):    true,
+}
+
+// isNative returns true if the field has one of the nativeTypes.
+func (f *Field) isNative() bool {
+	return nativeTypes[f.field.Type]
+}
+
+// layout returns the `layout` tag value used for time.Time fields, defaulting to time.RFC3339Nano.
+func (f *Field) layout() string {
+	if layout := f.field.Tag.Get(layoutTag); layout != "" {
+		return layout
+	}
+
+	return defaultLayout
+}
+
+// setNative sets the field from its natural string representation, eg: "30s" for a time.Duration.
+// Empty values set pointer fields and net.IP to nil.
+func (f *Field) setNative(rawVal string) error {
+	switch dst := f.value.Addr().Interface().(type) {
+	case *time.Duration:
+		d, err := time.ParseDuration(rawVal)
+		if err != nil {
+			// integers are accepted as nanoseconds, as previously decoded from JSON
+			ns, nsErr := strconv.ParseInt(rawVal, 10, 64)
+			if nsErr != nil {
+				return err
+			}
+			d = time.Duration(ns)
+		}
+		*dst = d
+
+	case *time.Time:
+		t, err := time.Parse(f.layout(), rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = t
+
+	case *url.URL:
+		u, err := url.Parse(rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = *u
+
+	case **url.URL:
+		if rawVal == "" {
+			*dst = nil
+			return nil
+		}
+		u, err := url.Parse(rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = u
+
+	case *net.IP:
+		if rawVal == "" {
+			*dst = nil
+			return nil
+		}
+		ip := net.ParseIP(rawVal)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address: %q", rawVal)
+		}
+		*dst = ip
+
+	case *net.IPNet:
+		_, n, err := net.ParseCIDR(rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = *n
+
+	case **net.IPNet:
+		if rawVal == "" {
+			*dst = nil
+			return nil
+		}
+		_, n, err := net.ParseCIDR(rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = n
+
+	case *netip.Addr:
+		return dst.UnmarshalText([]byte(rawVal))
+
+	case *netip.Prefix:
+		return dst.UnmarshalText([]byte(rawVal))
+
+	case *netip.AddrPort:
+		return dst.UnmarshalText([]byte(rawVal))
+
+	case **regexp.Regexp:
+		if rawVal == "" {
+			*dst = nil
+			return nil
+		}
+		re, err := regexp.Compile(rawVal)
+		if err != nil {
+			return err
+		}
+		*dst = re
+
+	case *slog.Level:
+		return dst.UnmarshalText([]byte(rawVal))
+
+	default:
+		return fmt.Errorf("unsupported type %s", f.field.Type)
+	}
+
+	return nil
+}
+
+// exportNative returns the natural string representation of the field, such that setNative can parse it.
+func (f *Field) exportNative() (string, error) {
+	switch v := f.value.Interface().(type) {
+	case time.Duration:
+		return v.String(), nil
+	case time.Time:
+		return v.Format(f.layout()), nil
+	case url.URL:
+		return v.String(), nil
+	case *url.URL:
+		if v == nil {
+			return "", nil
+		}
+		return v.String(), nil
+	case net.IP:
+		if v == nil {
+			return "", nil
+		}
+		return v.String(), nil
+	case net.IPNet:
+		return v.String(), nil
+	case *net.IPNet:
+		if v == nil {
+			return "", nil
+		}
+		return v.String(), nil
+	case netip.Addr:
+		buf, err := v.MarshalText()
+		return string(buf), err
+	case netip.Prefix:
+		buf, err := v.MarshalText()
+		return string(buf), err
+	case netip.AddrPort:
+		buf, err := v.MarshalText()
+		return string(buf), err
+	case *regexp.Regexp:
+		if v == nil {
+			return "", nil
+		}
+		return v.String(), nil
+	case slog.Level:
+		return v.String(), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", f.field.Type)
+}
@@ -0,0 +1,150 @@
+package conf
+
+import (
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNativeEndToEnd(t *testing.T) {
+	type Config struct {
+		Timeout  time.Duration  `env:"TIMEOUT"`
+		Start    time.Time      `env:"START"`
+		Date     time.Time      `env:"DATE" layout:"2006-01-02"`
+		URL      url.URL        `env:"URL"`
+		URLPtr   *url.URL       `env:"URL_PTR"`
+		IP       net.IP         `env:"IP"`
+		Net      net.IPNet      `env:"NET"`
+		NetPtr   *net.IPNet     `env:"NET_PTR"`
+		Addr     netip.Addr     `env:"ADDR"`
+		Prefix   netip.Prefix   `env:"PREFIX"`
+		AddrPort netip.AddrPort `env:"ADDR_PORT"`
+		Regexp   *regexp.Regexp `env:"REGEXP"`
+		Level    slog.Level     `env:"LEVEL"`
+		Nil      *url.URL       `env:"NIL"`
+	}
+
+	env := EnvMap{
+		"TIMEOUT":   "1m30s",
+		"START":     "2024-08-01T12:30:00.5+02:00",
+		"DATE":      "2024-08-01",
+		"URL":       "https://example.com/path?q=1",
+		"URL_PTR":   "postgres://user@localhost:5432/db",
+		"IP":        "192.168.1.1",
+		"NET":       "10.0.0.0/8",
+		"NET_PTR":   "fd00::/8",
+		"ADDR":      "::1",
+		"PREFIX":    "192.168.0.0/16",
+		"ADDR_PORT": "127.0.0.1:8080",
+		"REGEXP":    `^[a-z]+\d*$`,
+		"LEVEL":     "WARN",
+		"NIL":       "",
+	}
+
+	var cfg Config
+	if err := LoadEnvFrom(&cfg, env); err != nil {
+		t.Fatalf("LoadEnvFrom: %v", err)
+	}
+
+	if cfg.Timeout != 90*time.Second {
+		t.Errorf("Timeout = %v", cfg.Timeout)
+	}
+	if cfg.Date != time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Date = %v", cfg.Date)
+	}
+	if cfg.URLPtr == nil || cfg.URLPtr.Host != "localhost:5432" {
+		t.Errorf("URLPtr = %v", cfg.URLPtr)
+	}
+	if !cfg.IP.Equal(net.IPv4(192, 168, 1, 1)) {
+		t.Errorf("IP = %v", cfg.IP)
+	}
+	if !cfg.Regexp.MatchString("abc123") {
+		t.Errorf("Regexp = %v", cfg.Regexp)
+	}
+	if cfg.Level != slog.LevelWarn {
+		t.Errorf("Level = %v", cfg.Level)
+	}
+	if cfg.Nil != nil {
+		t.Errorf("Nil = %v", cfg.Nil)
+	}
+
+	// export the values and check that they round-trip
+	fields, err := FlattenStructFields(&cfg)
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+
+	exported := EnvMap{}
+	for _, field := range fields {
+		envVar, _ := field.EnvVar()
+		val, err := field.ExportValue()
+		if err != nil {
+			t.Fatalf("ExportValue(%s): %v", field.Path(), err)
+		}
+		exported[envVar] = val
+	}
+
+	for key, val := range env {
+		if key == "START" {
+			continue // time zone offset is formatted differently
+		}
+		if exported[key] != val {
+			t.Errorf("exported %s = %q, want %q", key, exported[key], val)
+		}
+	}
+
+	var got Config
+	if err := LoadEnvFrom(&got, exported); err != nil {
+		t.Fatalf("LoadEnvFrom: %v", err)
+	}
+
+	if got.Regexp.String() != cfg.Regexp.String() {
+		t.Errorf("round-trip Regexp = %v, want %v", got.Regexp, cfg.Regexp)
+	}
+	got.Regexp, cfg.Regexp = nil, nil
+	if !got.Start.Equal(cfg.Start) {
+		t.Errorf("round-trip Start = %v, want %v", got.Start, cfg.Start)
+	}
+	got.Start, cfg.Start = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round-trip got != want:\ngot:\n%+v\nwant:\n%+v", got, cfg)
+	}
+}
+
+func TestNative_invalid(t *testing.T) {
+	type Config struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+		IP      net.IP        `env:"IP"`
+	}
+
+	tests := []EnvMap{
+		{"TIMEOUT": "30 seconds"},
+		{"IP": "not an ip"},
+	}
+	for _, env := range tests {
+		var cfg Config
+		if err := LoadEnvFrom(&cfg, env); err == nil {
+			t.Errorf("expected an error for %v", env)
+		}
+	}
+}
+
+func TestNative_durationNanoseconds(t *testing.T) {
+	type Config struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	var cfg Config
+	if err := LoadEnvFrom(&cfg, EnvMap{"TIMEOUT": "30000000000"}); err != nil {
+		t.Fatalf("LoadEnvFrom: %v", err)
+	}
+
+	if cfg.Timeout != 30*time.Second {
+		t.Fatalf("Timeout = %v, want 30s", cfg.Timeout)
+	}
+}
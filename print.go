@@ -13,6 +13,7 @@ import (
 const (
 	maxPrintWidth = 90
 	secretMask    = "***"
+	defaultMarker = "(default)"
 )
 
 // Print wraps PrintToString and prints the result to stdout.
@@ -29,6 +30,7 @@ func Print(ptr any) {
 }
 
 // PrintToString returns a string representation of the config struct. Secrets are masked.
+// Fields that still hold the value of their `default` tag are marked with (default).
 // Example output:
 //
 //	Host      = "localhost"
@@ -38,6 +40,33 @@ func Print(ptr any) {
 //	  .User   = "user"
 //	  .Pass   ***
 func PrintToString(ptr any) string {
+	return printToString(ptr, nil)
+}
+
+// PrintWithProvenance wraps PrintToStringWithProvenance and prints the result to stdout.
+func PrintWithProvenance(ptr any, p Provenance) {
+	_, _ = fmt.Fprintln(os.Stdout, PrintToStringWithProvenance(ptr, p))
+}
+
+// PrintToStringWithProvenance is like PrintToString, with an extra column showing the source of each value.
+// Example output:
+//
+//	Host      = "localhost"   flag --host (overrides env HOST)
+//	Verbose   = false
+//	DB
+//	  .Name   = "app"         env DB_NAME
+//	  .User   = "user"        secret db-user
+//	  .Pass   ***             secret db-pass
+func PrintToStringWithProvenance(ptr any, p Provenance) string {
+	if p == nil {
+		p = Provenance{}
+	}
+
+	return printToString(ptr, p)
+}
+
+// printToString renders the config struct as a table. If p is not nil, the provenance of each field is included.
+func printToString(ptr any, p Provenance) string {
 	v := reflect.ValueOf(ptr)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
@@ -57,13 +86,11 @@ func PrintToString(ptr any) string {
 	table.SetCenterSeparator("-")
 
 	for _, field := range fields {
-		_, env := field.EnvVar()
-		_, flag := field.FlagName()
 		_, secret := field.SecretKey()
 
 		printVal := true
 		if field.value.Kind() == reflect.Struct {
-			printVal = env || flag || secret
+			printVal = field.isTagged() || field.isText()
 		}
 
 		name := field.name
@@ -74,6 +101,11 @@ func PrintToString(ptr any) string {
 		value := ""
 		if printVal {
 			value = fmt.Sprintf("= %#v", field.value.Interface())
+			if field.isText() {
+				if text, err := field.ExportValue(); err == nil {
+					value = "= " + text
+				}
+			}
 			if secret {
 				value = secretMask
 
@@ -88,13 +120,22 @@ func PrintToString(ptr any) string {
 					value = secretMask + " (len=0)"
 				}
 			}
+
+			if field.isDefault() {
+				value += " " + defaultMarker
+			}
 		}
 
 		if len(value) > maxPrintWidth-1 {
 			value = value[:maxPrintWidth-1] + "..."
 		}
 
-		table.Append([]string{name, value})
+		row := []string{name, value}
+		if p != nil {
+			row = append(row, p.describe(field.Path()))
+		}
+
+		table.Append(row)
 	}
 
 	table.Render()
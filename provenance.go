@@ -0,0 +1,89 @@
+package conf
+
+import (
+	"strings"
+)
+
+// Names of the built-in sources, as used in Origin.Source.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceSecret  = "secret"
+	SourceDotEnv  = "dotenv"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	SourceArg     = "arg"
+)
+
+// Origin describes a source that supplied a value for a field.
+type Origin struct {
+	Source string // eg: SourceEnv
+	Key    string // eg: the env var, flag name or secret key
+}
+
+func (o Origin) String() string {
+	if o.Key == "" {
+		return o.Source
+	}
+
+	return o.Source + " " + o.Key
+}
+
+// Provenance records which sources supplied the value of each field, keyed by the field path, eg: "DB.User".
+// The origins of a field are listed in the order they were applied, so the last origin is the one that holds,
+// and all previous origins were overridden.
+type Provenance map[string][]Origin
+
+// Origin returns the origin that supplied the current value of the field at path,
+// and a bool indicating if any source supplied a value.
+func (p Provenance) Origin(path string) (Origin, bool) {
+	origins := p[path]
+	if len(origins) == 0 {
+		return Origin{}, false
+	}
+
+	return origins[len(origins)-1], true
+}
+
+// Overridden returns the origins that supplied a value for the field at path, but were overridden by a later source.
+func (p Provenance) Overridden(path string) []Origin {
+	origins := p[path]
+	if len(origins) < 2 {
+		return nil
+	}
+
+	return origins[:len(origins)-1]
+}
+
+// describe returns a short description of the origins of the field at path, eg: "env DB_USER (overrides secret db-user)".
+func (p Provenance) describe(path string) string {
+	origin, ok := p.Origin(path)
+	if !ok {
+		return ""
+	}
+
+	desc := origin.String()
+
+	overridden := p.Overridden(path)
+	if len(overridden) > 0 {
+		var names []string
+		for i := len(overridden) - 1; i >= 0; i-- {
+			names = append(names, overridden[i].String())
+		}
+		desc += " (overrides " + strings.Join(names, ", ") + ")"
+	}
+
+	return desc
+}
+
+// newProvenance builds a Provenance from the origins recorded while loading fields.
+func newProvenance(fields []Field, origins [][]Origin) Provenance {
+	p := make(Provenance)
+	for i := range fields {
+		if len(origins[i]) > 0 {
+			p[fields[i].Path()] = origins[i]
+		}
+	}
+
+	return p
+}
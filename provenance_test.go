@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoad_provenance(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_PROV_HOST" flag:"--host" default:"localhost"`
+		Port int    `env:"TEST_PROV_PORT" default:"8080"`
+		DB   struct {
+			User string `env:"TEST_PROV_DB_USER" secret:"db-user"`
+			Pass string `env:"TEST_PROV_DB_PASS" secret:"db-pass"`
+		}
+	}
+
+	t.Parallel()
+
+	var prov Provenance
+	cfg, err := Load[Config](LoadCfg{
+		Env:           true,
+		Flags:         true,
+		SecretsLoader: mapSecrets{"db-user": "admin", "db-pass": "1337"},
+		EnvLookup:     EnvMap{"TEST_PROV_HOST": "example.com", "TEST_PROV_DB_USER": "user from env"},
+		Args:          []string{"--host=localhost:8888"},
+		Provenance:    &prov,
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Provenance{
+		"Host":    {{Source: SourceDefault}, {Source: SourceEnv, Key: "TEST_PROV_HOST"}, {Source: SourceFlag, Key: "--host"}},
+		"Port":    {{Source: SourceDefault}},
+		"DB.User": {{Source: SourceSecret, Key: "db-user"}, {Source: SourceEnv, Key: "TEST_PROV_DB_USER"}},
+		"DB.Pass": {{Source: SourceSecret, Key: "db-pass"}},
+	}
+	if !reflect.DeepEqual(prov, want) {
+		t.Fatalf("got != want:\ngot:\n%v\nwant:\n%v", prov, want)
+	}
+
+	if origin, _ := prov.Origin("DB.User"); origin.Source != SourceEnv {
+		t.Errorf("Origin(DB.User) = %v, want env", origin)
+	}
+
+	got := PrintToStringWithProvenance(&cfg, prov)
+	wantPrint := `--------------------------------------------------------------------------------------
+  Host      = "localhost:8888"   flag --host (overrides env TEST_PROV_HOST, default)
+  Port      = 8080 (default)     default
+  DB
+    .User   ***                  env TEST_PROV_DB_USER (overrides secret db-user)
+    .Pass   ***                  secret db-pass
+--------------------------------------------------------------------------------------
+`
+	if got != wantPrint {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", got, wantPrint)
+	}
+}
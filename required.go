@@ -0,0 +1,102 @@
+package conf
+
+import (
+	"strings"
+)
+
+// MissingFieldsError is returned when required fields were not supplied by any source.
+// It lists every missing field, so that all of them can be fixed in one go.
+type MissingFieldsError struct {
+	Fields []MissingField
+}
+
+// MissingField describes a required field that was not supplied,
+// along with the env var, flag, secret key, file key and positional arg that could have provided it.
+type MissingField struct {
+	Path   string
+	EnvVar string
+	Flag   string
+	Secret string
+	File   string
+	Arg    string
+}
+
+func (e *MissingFieldsError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("missing required configuration:")
+
+	for _, field := range e.Fields {
+		sb.WriteString("\n  ")
+		sb.WriteString(field.Path)
+
+		var keys []string
+		if field.EnvVar != "" {
+			keys = append(keys, "env "+field.EnvVar)
+		}
+		if field.Flag != "" {
+			keys = append(keys, "flag "+field.Flag)
+		}
+		if field.Secret != "" {
+			keys = append(keys, "secret "+field.Secret)
+		}
+		if field.File != "" {
+			keys = append(keys, "file "+field.File)
+		}
+		if field.Arg != "" {
+			keys = append(keys, "arg "+field.Arg)
+		}
+
+		if len(keys) > 0 {
+			sb.WriteString(" (" + strings.Join(keys, ", ") + ")")
+		}
+	}
+
+	return sb.String()
+}
+
+// checkRequired returns a *MissingFieldsError listing every required field that was not supplied by a source.
+// Default values do not count as a source. Only fields for which inScope returns true are checked.
+func checkRequired(fields []Field, origins [][]Origin, inScope func(f *Field) bool) error {
+	var missing []MissingField
+	for i := range fields {
+		field := &fields[i]
+		if !field.Required() || !inScope(field) || supplied(origins[i]) {
+			continue
+		}
+
+		envVar, _ := field.EnvVar()
+		flagName, _ := field.FlagName()
+		secretKey, _ := field.SecretKey()
+		fileKey, _ := field.FileKey()
+		arg, _ := field.Arg()
+
+		missing = append(missing, MissingField{
+			Path:   field.Path(),
+			EnvVar: envVar,
+			Flag:   flagName,
+			Secret: secretKey,
+			File:   fileKey,
+			Arg:    arg,
+		})
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &MissingFieldsError{Fields: missing}
+}
+
+// supplied returns true if any of the origins is a source other than a default value.
+func supplied(origins []Origin) bool {
+	for _, origin := range origins {
+		if origin.Source != SourceDefault {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allFields is used with checkRequired to check every field.
+func allFields(*Field) bool { return true }
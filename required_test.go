@@ -0,0 +1,96 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+)
+
+type mapSecrets map[string]string
+
+func (m mapSecrets) Load(key string) ([]byte, bool, error) {
+	val, ok := m[key]
+	return []byte(val), ok, nil
+}
+
+func TestField_Required(t *testing.T) {
+	type Config struct {
+		A string `env:"A,required"`
+		B string `flag:"--b" required:"true"`
+		C string `secret:"c, required"`
+		D string `env:"D"`
+		E string `required:"false"`
+	}
+
+	fields, err := FlattenStructFields(&Config{})
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+
+	want := []bool{true, true, true, false, false}
+	for i, field := range fields {
+		if got := field.Required(); got != want[i] {
+			t.Errorf("%s.Required() = %v, want %v", field.Path(), got, want[i])
+		}
+	}
+
+	if envVar, _ := fields[0].EnvVar(); envVar != "A" {
+		t.Errorf("EnvVar() = %q, want %q", envVar, "A")
+	}
+	if secretKey, _ := fields[2].SecretKey(); secretKey != "c" {
+		t.Errorf("SecretKey() = %q, want %q", secretKey, "c")
+	}
+}
+
+func TestLoad_missingRequired(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_REQ_HOST,required" flag:"--host"`
+		DB   struct {
+			User string `env:"TEST_REQ_DB_USER" secret:"db-user,required"`
+			Pass string `env:"TEST_REQ_DB_PASS" secret:"db-pass" required:"true"`
+			Name string `env:"TEST_REQ_DB_NAME,required"`
+		}
+	}
+
+	t.Parallel()
+
+	_, err := Load[Config](LoadCfg{
+		Env:           true,
+		Flags:         true,
+		SecretsLoader: mapSecrets{"db-user": "admin"},
+		EnvLookup:     EnvMap{"TEST_REQ_DB_NAME": "app"},
+		Args:          []string{},
+	})
+
+	var missingErr *MissingFieldsError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("expected *MissingFieldsError, got: %v", err)
+	}
+
+	want := `missing required configuration:
+  Host (env TEST_REQ_HOST, flag --host)
+  DB.Pass (env TEST_REQ_DB_PASS, secret db-pass)`
+	if err.Error() != want {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", err.Error(), want)
+	}
+}
+
+func TestLoadEnv_missingRequired(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_REQ_HOST,required"`
+		Pass string `secret:"db-pass,required"` // not checked by LoadEnv
+	}
+
+	t.Parallel()
+
+	var cfg Config
+	err := LoadEnvFrom(&cfg, EnvMap{})
+
+	var missingErr *MissingFieldsError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("expected *MissingFieldsError, got: %v", err)
+	}
+
+	if len(missingErr.Fields) != 1 || missingErr.Fields[0].Path != "Host" {
+		t.Fatalf("unexpected missing fields: %+v", missingErr.Fields)
+	}
+}
@@ -1,43 +1,282 @@
 package conf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 )
 
+// DefaultSecretsWorkers is the number of secrets loaded concurrently by LoadSecrets, LoadSecretsContext and Load,
+// unless set with LoadSecretsWorkers or LoadCfg.SecretsWorkers. Secrets are loaded one at a time by default,
+// so that SecretsLoader implementations do not need to be safe for concurrent use.
+const DefaultSecretsWorkers = 1
+
 // SecretsLoader interface allows any secret manager to be used, by wrapping it in a type that implements this interface.
+// If secrets are loaded concurrently, see LoadSecretsWorkers, implementations must be safe for concurrent use.
 type SecretsLoader interface {
 	// Load a secret from the source. Returns the secret value, a boolean indicating if the secret was found and an error.
 	// NOTE: Load should not return an error if the secret was not found, but should instead return "", false, nil.
 	Load(key string) ([]byte, bool, error)
 }
 
+// SecretsLoaderContext is like SecretsLoader, but LoadContext should return early when ctx is done,
+// eg: by passing ctx to the secret manager client.
+type SecretsLoaderContext interface {
+	// LoadContext loads a secret from the source, like SecretsLoader.Load.
+	LoadContext(ctx context.Context, key string) ([]byte, bool, error)
+}
+
+// BatchSecretsLoader can be implemented by a SecretsLoader or SecretsLoaderContext to load all secrets in one request.
+// If implemented, LoadMany is used instead of loading secrets one at a time.
+// If the context is done first, loading returns ctx.Err() without waiting for LoadMany, see BatchSecretsLoaderContext.
+type BatchSecretsLoader interface {
+	// LoadMany loads the secrets of keys. Keys that are not in the returned map were not found.
+	// NOTE: like Load, LoadMany should not return an error if a secret was not found.
+	LoadMany(keys []string) (map[string][]byte, error)
+}
+
+// BatchSecretsLoaderContext is like BatchSecretsLoader, but LoadManyContext should return early when ctx is done.
+// If implemented, LoadManyContext is used instead of LoadMany.
+type BatchSecretsLoaderContext interface {
+	// LoadManyContext loads the secrets of keys, like BatchSecretsLoader.LoadMany.
+	LoadManyContext(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// AdaptSecretsLoader adapts a SecretsLoader to the SecretsLoaderContext interface.
+// If loader implements SecretsLoaderContext, it is returned as is.
+// Otherwise LoadContext returns ctx.Err() when ctx is done, without waiting for Load to return.
+func AdaptSecretsLoader(loader SecretsLoader) SecretsLoaderContext {
+	if lc, ok := loader.(SecretsLoaderContext); ok {
+		return lc
+	}
+
+	return secretsLoaderAdapter{loader: loader}
+}
+
+type secretsLoaderAdapter struct {
+	loader SecretsLoader
+}
+
+func (a secretsLoaderAdapter) LoadContext(ctx context.Context, key string) ([]byte, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	// ctx can never be done, eg: context.Background()
+	if ctx.Done() == nil {
+		return a.loader.Load(key)
+	}
+
+	type result struct {
+		val []byte
+		ok  bool
+		err error
+	}
+
+	// buffered, so that the goroutine does not leak if ctx is done first
+	ch := make(chan result, 1)
+	go func() {
+		val, ok, err := a.loader.Load(key)
+		ch <- result{val: val, ok: ok, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.val, r.ok, r.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// batchLoaderAdapter adapts a BatchSecretsLoader to the BatchSecretsLoaderContext interface,
+// returning ctx.Err() when ctx is done without waiting for LoadMany to return.
+type batchLoaderAdapter struct {
+	loader BatchSecretsLoader
+}
+
+func (a batchLoaderAdapter) LoadManyContext(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// ctx can never be done, eg: context.Background()
+	if ctx.Done() == nil {
+		return a.loader.LoadMany(keys)
+	}
+
+	type result struct {
+		vals map[string][]byte
+		err  error
+	}
+
+	// buffered, so that the goroutine does not leak if ctx is done first
+	ch := make(chan result, 1)
+	go func() {
+		vals, err := a.loader.LoadMany(keys)
+		ch <- result{vals: vals, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.vals, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // LoadSecrets recursively scans struct fields for the secret tag then sets the values from the secret SecretsLoader.
+// Fields tagged as required, eg: `secret:"host,required"`, that are not found result in a *MissingFieldsError.
 // Eg:
 //
 //	type Config struct {
 //		Host string `secret:"host"`
 //	}
 func LoadSecrets(ptr any, source SecretsLoader) error {
+	return LoadSecretsContext(context.Background(), ptr, AdaptSecretsLoader(source))
+}
+
+// LoadSecretsContext is like LoadSecrets, but returns an error wrapping ctx.Err() if ctx is done before all secrets are loaded.
+// Eg: to limit the time spent loading secrets at startup
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := conf.LoadSecretsContext(ctx, &cfg, conf.AdaptSecretsLoader(secretMgr))
+func LoadSecretsContext(ctx context.Context, ptr any, source SecretsLoaderContext) error {
+	return LoadSecretsWorkers(ctx, ptr, source, DefaultSecretsWorkers)
+}
+
+// LoadSecretsWorkers is like LoadSecretsContext, but loads up to workers secrets concurrently,
+// eg: to speed up loading many secrets from a remote secret manager, which must be safe for concurrent use.
+// If workers is less than 1, DefaultSecretsWorkers is used.
+func LoadSecretsWorkers(ctx context.Context, ptr any, source SecretsLoaderContext, workers int) error {
 	fields, err := FlattenStructFields(ptr)
 	if err != nil {
 		return err
 	}
 
-	for _, field := range fields {
-		secretKey, secret := field.SecretKey()
-		if !secret {
-			continue
+	origins := make([][]Origin, len(fields))
+	if err := loadSecrets(ctx, fields, origins, source, workers); err != nil {
+		return err
+	}
+
+	return checkRequired(fields, origins, func(f *Field) bool {
+		_, secret := f.SecretKey()
+		return secret
+	})
+}
+
+// loadSecrets sets fields from the SecretsLoader, appending to origins[i] for every field that was set.
+// Secrets are loaded with LoadMany if source is a BatchSecretsLoader, otherwise up to workers secrets are loaded concurrently.
+// If secrets fail to load, the errors are joined in the order of the fields.
+func loadSecrets(ctx context.Context, fields []Field, origins [][]Origin, source SecretsLoaderContext, workers int) error {
+	var indexes []int
+	var keys []string
+	for i := range fields {
+		if secretKey, secret := fields[i].SecretKey(); secret {
+			indexes = append(indexes, i)
+			keys = append(keys, secretKey)
 		}
+	}
 
-		val, found, err := source.Load(secretKey)
-		if err != nil {
-			return fmt.Errorf("failed to load secret %q: %w", secretKey, err)
+	results, err := fetchSecrets(ctx, source, keys, workers)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for j, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("failed to load secret %q: %w", keys[j], r.err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for j, r := range results {
+		i := indexes[j]
+		if err := fields[i].setString(string(r.val), r.ok); err != nil {
+			return fmt.Errorf("failed to set field %q from secret source: %w", fields[i].field.Name, err)
 		}
 
-		if err := field.setString(string(val), found); err != nil {
-			return fmt.Errorf("failed to set field %q from secret source: %w", field.field.Name, err)
+		if r.ok {
+			origins[i] = append(origins[i], Origin{Source: SourceSecret, Key: keys[j]})
 		}
 	}
 
 	return nil
 }
+
+// secretResult is the result of loading a single secret.
+type secretResult struct {
+	val []byte
+	ok  bool
+	err error
+}
+
+// fetchSecrets loads the secrets of keys, returning a result for each key, in the same order.
+func fetchSecrets(ctx context.Context, source SecretsLoaderContext, keys []string, workers int) ([]secretResult, error) {
+	results := make([]secretResult, len(keys))
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	if batch, ok := batchLoader(source); ok {
+		var unique []string
+		for _, key := range keys {
+			if !contains(unique, key) {
+				unique = append(unique, key)
+			}
+		}
+
+		vals, err := batch.LoadManyContext(ctx, unique)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secrets: %w", err)
+		}
+
+		for j, key := range keys {
+			results[j].val, results[j].ok = vals[key]
+		}
+		return results, nil
+	}
+
+	if workers < 1 {
+		workers = DefaultSecretsWorkers
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for j, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j int, key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r := &results[j]
+			r.val, r.ok, r.err = source.LoadContext(ctx, key)
+		}(j, key)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// batchLoader returns source as a BatchSecretsLoaderContext, if it or the SecretsLoader it adapts
+// implements BatchSecretsLoaderContext or BatchSecretsLoader.
+func batchLoader(source SecretsLoaderContext) (BatchSecretsLoaderContext, bool) {
+	var loader any = source
+	if a, ok := source.(secretsLoaderAdapter); ok {
+		loader = a.loader
+	}
+
+	switch batch := loader.(type) {
+	case BatchSecretsLoaderContext:
+		return batch, true
+	case BatchSecretsLoader:
+		return batchLoaderAdapter{loader: batch}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,277 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hangingSecrets is a SecretsLoader that blocks until release is closed.
+type hangingSecrets struct {
+	release chan struct{}
+}
+
+func (s hangingSecrets) Load(string) ([]byte, bool, error) {
+	<-s.release
+	return []byte("late"), true, nil
+}
+
+// ctxSecrets is a SecretsLoaderContext that returns the key as the value, unless ctx is done.
+type ctxSecrets struct{}
+
+func (ctxSecrets) LoadContext(ctx context.Context, key string) ([]byte, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	return []byte(key), true, nil
+}
+
+type secretTestConfig struct {
+	Pass string `secret:"db-pass"`
+}
+
+func TestLoadSecretsContext(t *testing.T) {
+	var cfg secretTestConfig
+	if err := LoadSecretsContext(context.Background(), &cfg, ctxSecrets{}); err != nil {
+		t.Fatalf("LoadSecretsContext: %v", err)
+	}
+	if cfg.Pass != "db-pass" {
+		t.Fatalf("got %q, want %q", cfg.Pass, "db-pass")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := LoadSecretsContext(ctx, &secretTestConfig{}, ctxSecrets{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestLoadSecretsContext_adapter(t *testing.T) {
+	secrets := hangingSecrets{release: make(chan struct{})}
+	defer close(secrets.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := LoadSecretsContext(ctx, &secretTestConfig{}, AdaptSecretsLoader(secrets))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	var cfg secretTestConfig
+	if err := LoadSecrets(&cfg, mapSecrets{"db-pass": "1337"}); err != nil || cfg.Pass != "1337" {
+		t.Fatalf("LoadSecrets: %v, %+v", err, cfg)
+	}
+}
+
+func TestLoad_context(t *testing.T) {
+	secrets := hangingSecrets{release: make(chan struct{})}
+	defer close(secrets.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Load[secretTestConfig](LoadCfg{Context: ctx, SecretsLoader: secrets})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	got, err := Load[secretTestConfig](LoadCfg{Context: context.Background(), SecretsLoaderContext: ctxSecrets{}})
+	if err != nil || got.Pass != "db-pass" {
+		t.Fatalf("Load: %v, %+v", err, got)
+	}
+}
+
+// batchSecrets is a BatchSecretsLoader that records its requests.
+type batchSecrets struct {
+	mapSecrets
+	requests [][]string
+}
+
+func (s *batchSecrets) LoadMany(keys []string) (map[string][]byte, error) {
+	s.requests = append(s.requests, keys)
+
+	vals := make(map[string][]byte)
+	for _, key := range keys {
+		if val, ok := s.mapSecrets[key]; ok {
+			vals[key] = []byte(val)
+		}
+	}
+	return vals, nil
+}
+
+// slowSecrets is a SecretsLoader that records the maximum number of concurrent calls to Load,
+// and fails for keys starting with "bad", with keys earlier in the struct failing last.
+type slowSecrets struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (s *slowSecrets) Load(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.max {
+		s.max = s.inFlight
+	}
+	s.mu.Unlock()
+
+	delay := 5 * time.Millisecond
+	if key == "bad-1" {
+		delay *= 2
+	}
+	time.Sleep(delay)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	if strings.HasPrefix(key, "bad") {
+		return nil, false, errors.New("unavailable")
+	}
+	return []byte(key), true, nil
+}
+
+type manySecretsConfig struct {
+	A string `secret:"a"`
+	B string `secret:"b"`
+	C string `secret:"c"`
+	D string `secret:"d"`
+	E string `secret:"e"`
+	F string `secret:"a"`
+}
+
+func TestLoadSecrets_batch(t *testing.T) {
+	secrets := &batchSecrets{mapSecrets: mapSecrets{"a": "1", "b": "2", "e": "5"}}
+
+	var p Provenance
+	got, err := Load[manySecretsConfig](LoadCfg{SecretsLoader: secrets, Provenance: &p})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := manySecretsConfig{A: "1", B: "2", E: "5", F: "1"}
+	if got != want {
+		t.Fatalf("got != want: %+v != %+v", got, want)
+	}
+	if len(secrets.requests) != 1 || strings.Join(secrets.requests[0], ",") != "a,b,c,d,e" {
+		t.Fatalf("expected a single request for every unique key, got: %v", secrets.requests)
+	}
+	if origin, _ := p.Origin("E"); origin.String() != "secret e" {
+		t.Fatalf("unexpected origin: %v", origin)
+	}
+}
+
+// hangingBatchSecrets is a BatchSecretsLoader that blocks until release is closed.
+type hangingBatchSecrets struct {
+	hangingSecrets
+}
+
+func (s hangingBatchSecrets) LoadMany([]string) (map[string][]byte, error) {
+	<-s.release
+	return map[string][]byte{"db-pass": []byte("late")}, nil
+}
+
+// ctxBatchSecrets is a BatchSecretsLoaderContext that returns the keys as the values, unless ctx is done.
+type ctxBatchSecrets struct {
+	ctxSecrets
+}
+
+func (ctxBatchSecrets) LoadManyContext(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string][]byte)
+	for _, key := range keys {
+		vals[key] = []byte("batch " + key)
+	}
+	return vals, nil
+}
+
+func TestLoadSecrets_batchContext(t *testing.T) {
+	secrets := hangingBatchSecrets{hangingSecrets{release: make(chan struct{})}}
+	defer close(secrets.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Load[secretTestConfig](LoadCfg{Context: ctx, SecretsLoader: secrets})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	got, err := Load[secretTestConfig](LoadCfg{Context: context.Background(), SecretsLoaderContext: ctxBatchSecrets{}})
+	if err != nil || got.Pass != "batch db-pass" {
+		t.Fatalf("unexpected result: %+v, %v", got, err)
+	}
+}
+
+func TestLoadSecrets_concurrent(t *testing.T) {
+	secrets := &slowSecrets{}
+
+	got, err := Load[manySecretsConfig](LoadCfg{SecretsLoader: secrets, SecretsWorkers: 2})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.E != "e" || got.F != "a" {
+		t.Fatalf("unexpected config: %+v", got)
+	}
+	if secrets.max != 2 {
+		t.Fatalf("expected at most 2 concurrent loads, got %d", secrets.max)
+	}
+
+	// secrets are loaded one at a time by default
+	secrets = &slowSecrets{}
+	if err := LoadSecrets(&manySecretsConfig{}, secrets); err != nil {
+		t.Fatalf("LoadSecrets: %v", err)
+	}
+	if secrets.max != 1 {
+		t.Fatalf("expected secrets to be loaded one at a time, got %d concurrent loads", secrets.max)
+	}
+}
+
+func TestLoadSecrets_errorsOrderedByField(t *testing.T) {
+	var cfg struct {
+		A string `secret:"bad-1"` // fails last
+		B string `secret:"ok"`
+		C string `secret:"bad-2"`
+	}
+
+	err := LoadSecretsWorkers(context.Background(), &cfg, AdaptSecretsLoader(&slowSecrets{}), 3)
+
+	want := "failed to load secret \"bad-1\": unavailable\nfailed to load secret \"bad-2\": unavailable"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got != want:\n%v\n!=\n%s", err, want)
+	}
+}
+
+func TestLoad_secretsWorkersSources(t *testing.T) {
+	secrets := &slowSecrets{}
+
+	_, err := Load[manySecretsConfig](LoadCfg{Sources: []Source{SecretSource(secrets)}, SecretsWorkers: 2})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if secrets.max != 2 {
+		t.Fatalf("expected at most 2 concurrent loads, got %d", secrets.max)
+	}
+}
+
+func TestLoadSecretsWorkers(t *testing.T) {
+	secrets := &slowSecrets{}
+
+	var cfg manySecretsConfig
+	if err := LoadSecretsWorkers(context.Background(), &cfg, AdaptSecretsLoader(secrets), 3); err != nil {
+		t.Fatalf("LoadSecretsWorkers: %v", err)
+	}
+	if cfg.E != "e" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if secrets.max != 3 {
+		t.Fatalf("expected at most 3 concurrent loads, got %d", secrets.max)
+	}
+}
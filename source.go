@@ -0,0 +1,201 @@
+package conf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Source supplies the values of fields to Load, see LoadCfg.Sources.
+// Eg: a source for a feature flag service
+//
+//	type featureFlags struct{ client *flags.Client }
+//
+//	func (s featureFlags) Name() string { return "feature-flags" }
+//
+//	func (s featureFlags) Lookup(field *conf.Field) (string, string, bool, error) {
+//		key := "myapp." + strings.ToLower(field.Path())
+//		val, ok, err := s.client.Get(key)
+//		return val, key, ok, err
+//	}
+type Source interface {
+	// Name of the source, used as the Origin.Source of the values it supplies, eg: "env".
+	Name() string
+
+	// Lookup returns the raw value of the field, the key it was found under, eg: the env var,
+	// and a bool indicating whether the source has a value for the field.
+	// Lookup is called for every field, including nested structs, and should return false for fields it does not supply.
+	// Values are decoded like env vars, eg: "30s" for a time.Duration or "a,b,c" for a []string.
+	Lookup(field *Field) (val, key string, found bool, err error)
+}
+
+// fieldsLoader is implemented by built-in sources that load all fields at once,
+// eg: to count repeated flags or to record the file that supplied each value.
+type fieldsLoader interface {
+	load(ctx context.Context, fields []Field, origins [][]Origin) error
+}
+
+// lookupField implements Source.Lookup for built-in sources, by loading a copy of field with loader,
+// so that Lookup decodes values like Load. The value is returned as exported by Field.ExportValue.
+func lookupField(loader fieldsLoader, field *Field) (string, string, bool, error) {
+	tmp := *field
+	tmp.value = reflect.New(field.field.Type).Elem()
+
+	origins := make([][]Origin, 1)
+	if err := loader.load(context.Background(), []Field{tmp}, origins); err != nil {
+		return "", "", false, err
+	}
+	if len(origins[0]) == 0 {
+		return "", "", false, nil
+	}
+
+	val, err := tmp.ExportValue()
+	return val, origins[0][len(origins[0])-1].Key, true, err
+}
+
+// loadSource sets fields from source, appending to origins[i] for every field that was set.
+// An error wrapping ctx.Err() is returned if ctx is done before all fields are loaded.
+func loadSource(ctx context.Context, fields []Field, origins [][]Origin, source Source) error {
+	if loader, ok := source.(fieldsLoader); ok {
+		return loader.load(ctx, fields, origins)
+	}
+
+	for i := range fields {
+		field := &fields[i]
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to load field %q from %s: %w", field.field.Name, source.Name(), err)
+		}
+
+		val, key, ok, err := source.Lookup(field)
+		if err != nil {
+			return fmt.Errorf("failed to load field %q from %s: %w", field.field.Name, source.Name(), err)
+		}
+		if !ok {
+			continue
+		}
+
+		if err := field.setString(val, true); err != nil {
+			return fmt.Errorf("failed to set field %q from %s: %w", field.field.Name, source.Name(), err)
+		}
+
+		origins[i] = append(origins[i], Origin{Source: source.Name(), Key: key})
+	}
+
+	return nil
+}
+
+// EnvSource returns a Source for fields with the `env` tag, which looks up env vars in env, eg: EnvMap.
+// If env is nil, the process environment is used.
+func EnvSource(env EnvLookup) Source {
+	return envSource{env: env}
+}
+
+type envSource struct {
+	env EnvLookup // nil for the process environment
+}
+
+func (s envSource) lookup() EnvLookup {
+	if s.env == nil {
+		return osEnv
+	}
+
+	return s.env
+}
+
+func (s envSource) Name() string { return SourceEnv }
+
+func (s envSource) Lookup(field *Field) (string, string, bool, error) {
+	return lookupField(s, field)
+}
+
+func (s envSource) load(_ context.Context, fields []Field, origins [][]Origin) error {
+	return loadEnv(fields, origins, s.lookup())
+}
+
+// FlagSource returns a Source for fields with the `flag` and `arg` tags, which parses args, eg: os.Args[1:].
+// Repeated flags, counters, negated boolean flags and positional args are handled like LoadFlags.
+// Lookup only knows the flag of the field it is called with,
+// so clustered short flags of other fields, eg: -vd, are not tokenized like Load does.
+func FlagSource(args []string) Source {
+	return flagSource{args: args}
+}
+
+type flagSource struct {
+	args []string
+}
+
+func (s flagSource) Name() string { return SourceFlag }
+
+func (s flagSource) Lookup(field *Field) (string, string, bool, error) {
+	return lookupField(s, field)
+}
+
+func (s flagSource) load(_ context.Context, fields []Field, origins [][]Origin) error {
+	return loadFlags(fields, origins, s.args)
+}
+
+// SecretSource returns a Source for fields with the `secret` tag, which loads secrets from loader.
+func SecretSource(loader SecretsLoader) Source {
+	return secretSource{loader: AdaptSecretsLoader(loader), workers: DefaultSecretsWorkers}
+}
+
+// SecretSourceContext is like SecretSource, but secrets are loaded with LoadCfg.Context, see SecretsLoaderContext.
+// Lookup loads secrets with context.Background().
+func SecretSourceContext(loader SecretsLoaderContext) Source {
+	return secretSource{loader: loader, workers: DefaultSecretsWorkers}
+}
+
+type secretSource struct {
+	loader  SecretsLoaderContext
+	workers int // secrets loaded concurrently, see LoadCfg.SecretsWorkers
+}
+
+func (s secretSource) Name() string { return SourceSecret }
+
+func (s secretSource) Lookup(field *Field) (string, string, bool, error) {
+	return lookupField(s, field)
+}
+
+func (s secretSource) load(ctx context.Context, fields []Field, origins [][]Origin) error {
+	return loadSecrets(ctx, fields, origins, s.loader, s.workers)
+}
+
+// FileSource returns a Source for JSON, YAML or TOML config files, which are applied in order, see LoadFiles.
+// The files are read every time the source is loaded, or Lookup is called.
+func FileSource(paths ...string) Source {
+	return fileSource{paths: paths}
+}
+
+type fileSource struct {
+	paths []string
+}
+
+func (s fileSource) Name() string { return SourceFile }
+
+func (s fileSource) Lookup(field *Field) (string, string, bool, error) {
+	return lookupField(s, field)
+}
+
+func (s fileSource) load(_ context.Context, fields []Field, origins [][]Origin) error {
+	return loadFiles(fields, origins, s.paths)
+}
+
+// DotEnvSource returns a Source for fields with the `env` tag, which reads .env files in order, see LoadDotEnv.
+// The files are read every time the source is loaded, or Lookup is called.
+func DotEnvSource(paths ...string) Source {
+	return dotEnvSource{paths: paths}
+}
+
+type dotEnvSource struct {
+	paths []string
+}
+
+func (s dotEnvSource) Name() string { return SourceDotEnv }
+
+func (s dotEnvSource) Lookup(field *Field) (string, string, bool, error) {
+	return lookupField(s, field)
+}
+
+func (s dotEnvSource) load(_ context.Context, fields []Field, origins [][]Origin) error {
+	return loadDotEnv(fields, origins, s.paths)
+}
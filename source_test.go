@@ -0,0 +1,173 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// featureFlags is a custom Source that supplies fields by their lower case path.
+type featureFlags map[string]string
+
+func (s featureFlags) Name() string { return "feature-flags" }
+
+func (s featureFlags) Lookup(field *Field) (string, string, bool, error) {
+	key := strings.ToLower(field.Path())
+	if key == "broken" {
+		return "", key, false, errors.New("service unavailable")
+	}
+
+	val, ok := s[key]
+	return val, key, ok, nil
+}
+
+func TestLoad_sources(t *testing.T) {
+	type Config struct {
+		Host    string `env:"HOST" secret:"host" flag:"--host"`
+		Debug   bool   `env:"DEBUG"`
+		Feature bool
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("host: file\nfeature: false\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	var p Provenance
+	got, err := Load[Config](LoadCfg{
+		Sources: []Source{
+			FileSource(path),
+			EnvSource(EnvMap{"HOST": "env", "DEBUG": "true"}),
+			SecretSource(mapSecrets{"host": "secret"}), // secrets override env
+			featureFlags{"feature": "on"},
+		},
+		Provenance: &p,
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Config{Host: "secret", Debug: true, Feature: true}
+	if got != want {
+		t.Fatalf("got != want: %+v != %+v", got, want)
+	}
+
+	wantProvenance := map[string]string{
+		"Host":    "secret host (overrides env HOST, file " + path + ":Host)",
+		"Debug":   "env DEBUG",
+		"Feature": "feature-flags feature (overrides file " + path + ":Feature)",
+	}
+	for path, want := range wantProvenance {
+		if got := p.describe(path); got != want {
+			t.Errorf("provenance of %s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestLoad_sourcesFlags(t *testing.T) {
+	type Config struct {
+		Host    string `env:"HOST" flag:"--host"`
+		Verbose int    `flag:"-v" count:"true"`
+	}
+
+	// flags before env, so env overrides flags
+	got, err := Load[Config](LoadCfg{
+		Sources: []Source{FlagSource([]string{"--host", "flag", "-vv"}), EnvSource(EnvMap{"HOST": "env"})},
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := (Config{Host: "env", Verbose: 2}); got != want {
+		t.Fatalf("got != want: %+v != %+v", got, want)
+	}
+
+	_, err = Load[Config](LoadCfg{Sources: []Source{FlagSource([]string{"--help"})}})
+	if !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got: %v", err)
+	}
+}
+
+func TestLoad_sourceError(t *testing.T) {
+	type Config struct {
+		Broken string
+	}
+
+	_, err := Load[Config](LoadCfg{Sources: []Source{featureFlags{}}})
+
+	want := `failed to load field "Broken" from feature-flags: service unavailable`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got != want: %v != %s", err, want)
+	}
+}
+
+func TestSource_lookup(t *testing.T) {
+	var cfg struct {
+		Host string `env:"HOST" flag:"--host" secret:"host"`
+	}
+	fields, err := FlattenStructFields(&cfg)
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("HOST=dotenv\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		source  Source
+		wantVal string
+		wantKey string
+	}{
+		{source: EnvSource(EnvMap{"HOST": "env"}), wantVal: "env", wantKey: "HOST"},
+		{source: FlagSource([]string{"--host=a", "--host", "flag"}), wantVal: "flag", wantKey: "--host"},
+		{source: SecretSource(mapSecrets{"host": "secret"}), wantVal: "secret", wantKey: "host"},
+		{source: DotEnvSource(path), wantVal: "dotenv", wantKey: path + ":HOST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source.Name(), func(t *testing.T) {
+			val, key, ok, err := tt.source.Lookup(&fields[0])
+			if err != nil || !ok || val != tt.wantVal || key != tt.wantKey {
+				t.Fatalf("got (%q, %q, %v, %v), want (%q, %q, true, nil)", val, key, ok, err, tt.wantVal, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestSource_lookupLikeLoad(t *testing.T) {
+	var cfg struct {
+		Debug   bool     `flag:"--debug"`
+		Verbose int      `flag:"-v" count:"true"`
+		Tags    []string `flag:"--tag"`
+		Host    string
+	}
+	fields, err := FlattenStructFields(&cfg)
+	if err != nil {
+		t.Fatalf("FlattenStructFields: %v", err)
+	}
+
+	flags := FlagSource([]string{"--debug", "--no-debug", "-v", "-v", "--tag", "a", "--tag", "b,c"})
+	wantFlags := []string{"false", "2", `["a","b","c"]`}
+	for i, want := range wantFlags {
+		val, _, ok, err := flags.Lookup(&fields[i])
+		if err != nil || !ok || val != want {
+			t.Fatalf("Lookup(%s): got (%q, %v, %v), want %q", fields[i].Path(), val, ok, err, want)
+		}
+	}
+
+	// files are read on every lookup
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := FileSource(path)
+	for _, host := range []string{"a", "b"} {
+		if err := os.WriteFile(path, []byte("host: "+host+"\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+
+		val, key, ok, err := file.Lookup(&fields[3])
+		if err != nil || !ok || val != host || key != path+":Host" {
+			t.Fatalf("Lookup: got (%q, %q, %v, %v), want %q", val, key, ok, err, host)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package conf
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags registers a flag.Value in fs for every field of ptr with a `flag` tag,
+// so that conf fields are parsed by fs.Parse and listed by fs.PrintDefaults alongside other flags.
+// Flags are registered without their dashes, eg: `flag:"--host"` is registered as "host",
+// which the flag package accepts as both -host and --host.
+// Values are decoded like LoadFlags, eg: repeated flags are collected into slice and map fields,
+// and counter fields count occurrences. The current values of the fields are shown as the defaults,
+// so call LoadDefaults first to show the values of `default` tags.
+// An error is returned if a flag is already registered in fs.
+// Eg:
+//
+//	var cfg Config
+//	_ = conf.LoadDefaults(&cfg)
+//	if err := conf.RegisterFlags(flag.CommandLine, &cfg); err != nil {
+//		panic(err)
+//	}
+//	flag.Parse()
+func RegisterFlags(fs *flag.FlagSet, ptr any) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	for i := range fields {
+		flagName, flag := fields[i].FlagName()
+		if !flag {
+			continue
+		}
+
+		name := strings.TrimLeft(flagName, flagPrefix)
+		if fs.Lookup(name) != nil {
+			return fmt.Errorf("failed to register field %q: flag %q is already registered", fields[i].field.Name, name)
+		}
+
+		fs.Var(&fieldValue{field: &fields[i]}, name, fields[i].Description())
+
+		// zero values are not shown as defaults by fs.PrintDefaults
+		if fields[i].value.IsZero() {
+			fs.Lookup(name).DefValue = ""
+		}
+	}
+
+	return nil
+}
+
+// fieldValue implements flag.Value for a Field.
+type fieldValue struct {
+	field   *Field
+	rawVals []string // values of repeated flags, for fields that accumulate
+	count   int      // occurrences of the flag, for counter fields
+}
+
+func (v *fieldValue) String() string {
+	// the flag package calls String on a zero fieldValue to detect zero defaults
+	if v.field == nil {
+		return ""
+	}
+
+	val, err := v.field.ExportValue()
+	if err != nil {
+		return ""
+	}
+
+	return val
+}
+
+func (v *fieldValue) Set(rawVal string) error {
+	switch {
+	case v.field.Counter():
+		n, err := countFlag([]string{rawVal})
+		if err != nil || n == 0 {
+			return err
+		}
+
+		v.count += n
+		_, err = loadCounter(v.field, v.count)
+		return err
+
+	case v.field.accumulates():
+		v.rawVals = append(v.rawVals, rawVal)
+		return v.field.setStrings(v.rawVals)
+	}
+
+	return v.field.setString(rawVal, true)
+}
+
+// IsBoolFlag reports whether the flag can be passed without a value, ie: bool and counter fields.
+func (v *fieldValue) IsBoolFlag() bool {
+	return v.field.value.Kind() == reflect.Bool || v.field.Counter()
+}
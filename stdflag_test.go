@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	type Config struct {
+		Host    string        `flag:"--host" default:"localhost" usage:"host to listen on"`
+		Port    int           `flag:"--port"`
+		Debug   bool          `flag:"--debug"`
+		Verbose int           `flag:"-v" count:"true"`
+		Tags    []string      `flag:"--tag"`
+		Timeout time.Duration `flag:"--timeout" default:"5s"`
+		Env     string        `env:"ENV"`
+	}
+
+	var cfg Config
+	if err := LoadDefaults(&cfg); err != nil {
+		t.Fatalf("LoadDefaults: %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	thirdParty := fs.String("third-party", "", "registered by another library")
+	if err := RegisterFlags(fs, &cfg); err != nil {
+		t.Fatalf("RegisterFlags: %v", err)
+	}
+
+	err := fs.Parse([]string{"--port", "8080", "-debug", "-v", "-v=false", "-v", "--tag", "a", "--tag=b,c", "--timeout=1m", "-third-party", "x", "file.txt"})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := Config{Host: "localhost", Port: 8080, Debug: true, Verbose: 2, Tags: []string{"a", "b", "c"}, Timeout: time.Minute}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got != want: %+v != %+v", cfg, want)
+	}
+	if *thirdParty != "x" || !reflect.DeepEqual(fs.Args(), []string{"file.txt"}) {
+		t.Fatalf("unexpected third party flag %q or args %v", *thirdParty, fs.Args())
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fs.SetOutput(buf)
+	fs.PrintDefaults()
+	for _, s := range []string{"-host value\n    \thost to listen on (default localhost)", "-timeout value\n    \t (default 5s)", "-debug"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("PrintDefaults does not contain %q:\n%s", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "ENV") || strings.Contains(buf.String(), "(default 0)") || strings.Contains(buf.String(), "panic") {
+		t.Fatalf("unexpected PrintDefaults output:\n%s", buf.String())
+	}
+}
+
+func TestRegisterFlags_invalid(t *testing.T) {
+	var cfg struct {
+		Port int  `flag:"--port"`
+		Dup  bool `flag:"-port"`
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := RegisterFlags(fs, &cfg); err == nil {
+		t.Fatal("expected an error for a duplicate flag")
+	}
+
+	fs.SetOutput(bytes.NewBuffer(nil))
+	if err := fs.Parse([]string{"--port", "abc"}); err == nil {
+		t.Fatal("expected an error for an invalid int")
+	}
+}
@@ -0,0 +1,229 @@
+package conf
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnknownFlagsError is returned in strict mode when CLI args contain flags that are not declared by any field.
+type UnknownFlagsError struct {
+	Flags []UnknownKey
+}
+
+// UnknownKey describes an undeclared flag or env var, with the closest declared name as a suggestion (if any).
+type UnknownKey struct {
+	Name       string
+	Suggestion string
+}
+
+func (e *UnknownFlagsError) Error() string {
+	return formatUnknownKeys("unknown flags:", e.Flags)
+}
+
+// UnknownEnvError is returned in strict mode when env vars with the strict prefix are not declared by any field.
+type UnknownEnvError struct {
+	Vars []UnknownKey
+}
+
+func (e *UnknownEnvError) Error() string {
+	return formatUnknownKeys("unknown env vars:", e.Vars)
+}
+
+func formatUnknownKeys(header string, keys []UnknownKey) string {
+	var sb strings.Builder
+	sb.WriteString(header)
+
+	for _, key := range keys {
+		sb.WriteString("\n  ")
+		sb.WriteString(key.Name)
+		if key.Suggestion != "" {
+			sb.WriteString(" (did you mean " + key.Suggestion + "?)")
+		}
+	}
+
+	return sb.String()
+}
+
+// LoadFlagsStrict is like LoadFlags, but returns an *UnknownFlagsError if os.Args contain flags
+// that are not declared by any field. The error includes suggestions for misspelled flags, eg:
+//
+//	unknown flags:
+//	  --hots (did you mean --host?)
+func LoadFlagsStrict(ptr any) error {
+	return LoadFlagsStrictFrom(ptr, os.Args[1:])
+}
+
+// LoadFlagsStrictFrom is like LoadFlagsStrict, but reads flags from args instead of os.Args[1:].
+func LoadFlagsStrictFrom(ptr any, args []string) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	if err := checkUnknownFlags(fields, args); err != nil {
+		return err
+	}
+
+	return LoadFlagsFrom(ptr, args)
+}
+
+// LoadEnvStrict is like LoadEnv, but returns an *UnknownEnvError if any env var starting with prefix
+// is not declared by an `env` tag. The error includes suggestions for misspelled env vars, eg:
+//
+//	unknown env vars:
+//	  MYAPP_DB_USR (did you mean MYAPP_DB_USER?)
+func LoadEnvStrict(ptr any, prefix string) error {
+	return LoadEnvStrictFrom(ptr, prefix, Environ(os.Environ()))
+}
+
+// LoadEnvStrictFrom is like LoadEnvStrict, but looks up env vars from env instead of the process environment.
+// Eg:
+//
+//	err := LoadEnvStrictFrom(&cfg, "MYAPP_", EnvMap{"MYAPP_HOST": "localhost"})
+func LoadEnvStrictFrom(ptr any, prefix string, env EnvMap) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	if err := checkUnknownEnv(fields, env.keys(), prefix); err != nil {
+		return err
+	}
+
+	return LoadEnvFrom(ptr, env)
+}
+
+// checkUnknownEnv returns an *UnknownEnvError listing every env var in keys
+// that starts with prefix but is not declared by a field.
+func checkUnknownEnv(fields []Field, keys []string, prefix string) error {
+	var declared []string
+	for _, field := range fields {
+		if envVar, env := field.EnvVar(); env {
+			declared = append(declared, envVar)
+		}
+	}
+
+	var names []string
+	for _, name := range keys {
+		if strings.HasPrefix(name, prefix) && !contains(declared, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var unknown []UnknownKey
+	for _, name := range names {
+		unknown = append(unknown, UnknownKey{
+			Name:       name,
+			Suggestion: suggest(name, declared),
+		})
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	return &UnknownEnvError{Vars: unknown}
+}
+
+// checkUnknownFlags returns an *UnknownFlagsError listing every flag in args that is not declared by a field.
+// Args after the "--" terminator are not checked.
+func checkUnknownFlags(fields []Field, args []string) error {
+	kinds := flagKinds(fields)
+
+	var declared []string
+	for name := range kinds {
+		declared = append(declared, name)
+	}
+
+	var unknown []UnknownKey
+	for _, name := range parseArgs(args, kinds).Flags() {
+		if _, ok := kinds[name]; ok {
+			continue
+		}
+
+		unknown = append(unknown, UnknownKey{
+			Name:       name,
+			Suggestion: suggest(name, declared),
+		})
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	return &UnknownFlagsError{Flags: unknown}
+}
+
+// suggest returns the candidate closest to name by edit distance, or "" if none of them are close enough.
+func suggest(name string, candidates []string) string {
+	// sort for deterministic suggestions when distances are equal
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	maxDist := len(name) / 3
+	if maxDist < 2 {
+		maxDist = 2
+	}
+
+	best, bestDist := "", maxDist+1
+	for _, candidate := range sorted {
+		if dist := editDistance(name, candidate); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,130 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadFlagsStrict(t *testing.T) {
+	type Config struct {
+		Host    string `flag:"--host"`
+		Verbose bool   `flag:"-v"`
+		Offset  int    `flag:"--offset"`
+		DB      struct {
+			User string `flag:"--db-user"`
+		}
+	}
+
+	args := []string{"--hots=example.com", "-v", "--offset", "-5", "--db-usr", "admin", "--xyz", "--", "--ignored"}
+
+	var cfg Config
+	err := LoadFlagsStrictFrom(&cfg, args)
+
+	var unknownErr *UnknownFlagsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected *UnknownFlagsError, got: %v", err)
+	}
+
+	want := `unknown flags:
+  --hots (did you mean --host?)
+  --db-usr (did you mean --db-user?)
+  --xyz`
+	if err.Error() != want {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", err.Error(), want)
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"--host", "--hots", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEnvStrict(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_STRICT_HOST"`
+		DB   struct {
+			User string `env:"TEST_STRICT_DB_USER"`
+		}
+	}
+
+	t.Setenv("TEST_STRICT_HOST", "localhost")
+	t.Setenv("TEST_STRICT_DB_USR", "admin")
+	t.Setenv("TEST_STRICT_UNRELATED", "xyz")
+	t.Setenv("OTHER_PREFIX", "ignored")
+
+	var cfg Config
+	err := LoadEnvStrict(&cfg, "TEST_STRICT_")
+
+	var unknownErr *UnknownEnvError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected *UnknownEnvError, got: %v", err)
+	}
+
+	want := `unknown env vars:
+  TEST_STRICT_DB_USR (did you mean TEST_STRICT_DB_USER?)
+  TEST_STRICT_UNRELATED`
+	if err.Error() != want {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", err.Error(), want)
+	}
+}
+
+func TestLoad_strictEnvLookup(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_STRICT_HOST"`
+	}
+
+	t.Parallel()
+
+	_, err := Load[Config](LoadCfg{
+		Env:             true,
+		EnvLookup:       EnvMap{"TEST_STRICT_HOST": "localhost", "TEST_STRICT_HOTS": "typo"},
+		StrictEnvPrefix: "TEST_STRICT_",
+	})
+
+	var unknownErr *UnknownEnvError
+	if !errors.As(err, &unknownErr) || unknownErr.Vars[0].Suggestion != "TEST_STRICT_HOST" {
+		t.Fatalf("expected *UnknownEnvError, got: %v", err)
+	}
+
+	// a lookup function cannot list the env vars
+	_, err = Load[Config](LoadCfg{
+		Env:             true,
+		EnvLookup:       LookupFunc(func(string) (string, bool) { return "", false }),
+		StrictEnvPrefix: "TEST_STRICT_",
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestLoadEnvStrictFrom(t *testing.T) {
+	type Config struct {
+		Host string `env:"TEST_STRICT_HOST"`
+	}
+
+	t.Parallel()
+
+	var cfg Config
+	err := LoadEnvStrictFrom(&cfg, "TEST_STRICT_", EnvMap{"TEST_STRICT_HOST": "localhost", "TEST_STRICT_HOTS": "typo"})
+
+	var unknownErr *UnknownEnvError
+	if !errors.As(err, &unknownErr) || unknownErr.Vars[0].Suggestion != "TEST_STRICT_HOST" {
+		t.Fatalf("expected *UnknownEnvError, got: %v", err)
+	}
+
+	if err := LoadEnvStrictFrom(&cfg, "TEST_STRICT_", EnvMap{"TEST_STRICT_HOST": "localhost"}); err != nil || cfg.Host != "localhost" {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,197 @@
+package conf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// ErrHelp is returned (wrapped in a *HelpError) by LoadFlags and Load when -h or --help is passed.
+var ErrHelp = errors.New("conf: help requested")
+
+// HelpError is returned by LoadFlags and Load when -h or --help is passed.
+// Usage contains the help text generated by Usage, and errors.Is(err, ErrHelp) reports true.
+type HelpError struct {
+	Usage string
+}
+
+func (e *HelpError) Error() string {
+	return e.Usage
+}
+
+func (e *HelpError) Unwrap() error {
+	return ErrHelp
+}
+
+var helpFlags = []string{"-h", "-help", "--help"}
+
+// Usage returns a help screen generated from the struct fields of ptr.
+// Every field with a `flag` tag is listed with its type, description (from the `usage` or `desc` tag),
+// default value, env var and secret key. Fields with an `env` tag but no `flag` tag are listed separately,
+// as are fields with an `arg` tag, eg: <dir> for `arg:"0"` or [files...] for `arg:"rest"`,
+// and top level subcommands, eg: fields with the `cmd:"migrate"` tag.
+// Eg:
+//
+//	type Config struct {
+//		Host string `flag:"--host" env:"HOST" default:"localhost" usage:"host to listen on"`
+//	}
+//
+// Output:
+//
+//	Flags:
+//	  --host string   host to listen on (default "localhost", env HOST)
+func Usage(ptr any) string {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return "ERROR: conf.Usage: " + err.Error()
+	}
+
+	var cmdFields, argFields, flagFields, envFields []Field
+	for _, field := range fields {
+		if _, cmd := field.Command(); cmd && parentCommand(fields, &field) == "" {
+			cmdFields = append(cmdFields, field)
+		} else if _, arg := field.Arg(); arg {
+			argFields = append(argFields, field)
+		} else if _, flag := field.FlagName(); flag {
+			flagFields = append(flagFields, field)
+		} else if _, env := field.EnvVar(); env {
+			envFields = append(envFields, field)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if len(cmdFields) > 0 {
+		buf.WriteString("Commands:\n")
+		tw := tabwriter.NewWriter(buf, 0, 0, 3, ' ', 0)
+		for _, field := range cmdFields {
+			cmd, _ := field.Command()
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\n", cmd, field.Description())
+		}
+		_ = tw.Flush()
+	}
+
+	if len(argFields) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("Arguments:\n")
+		writeUsageSection(buf, argFields, func(f *Field) string {
+			if arg, _ := f.Arg(); arg == argRest {
+				return "[" + strings.ToLower(f.name) + "...]"
+			}
+			return "<" + strings.ToLower(f.name) + ">"
+		})
+	}
+
+	if len(flagFields) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("Flags:\n")
+		writeUsageSection(buf, flagFields, func(f *Field) string {
+			flagName, _ := f.FlagName()
+			return flagName
+		})
+	}
+
+	if len(envFields) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("Environment variables:\n")
+		writeUsageSection(buf, envFields, func(f *Field) string {
+			envVar, _ := f.EnvVar()
+			return envVar
+		})
+	}
+
+	return buf.String()
+}
+
+func writeUsageSection(buf *bytes.Buffer, fields []Field, name func(f *Field) string) {
+	tw := tabwriter.NewWriter(buf, 0, 0, 3, ' ', 0)
+	for i := range fields {
+		field := &fields[i]
+
+		key := name(field)
+		if field.value.Kind() != reflect.Bool && !field.Counter() {
+			key += " " + field.field.Type.String()
+		}
+
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", key, field.usageDetails())
+	}
+	_ = tw.Flush()
+}
+
+// usageDetails returns the description of the field followed by its default value, env var and secret key.
+func (f *Field) usageDetails() string {
+	var details []string
+	if defaultVal, ok := f.usageDefault(); ok {
+		details = append(details, "default "+defaultVal)
+	}
+	if _, flag := f.FlagName(); flag {
+		if envVar, env := f.EnvVar(); env {
+			details = append(details, "env "+envVar)
+		}
+	}
+	if secretKey, secret := f.SecretKey(); secret {
+		details = append(details, "secret "+secretKey)
+	}
+	if f.Counter() {
+		details = append(details, "repeatable")
+	}
+	if f.Required() {
+		details = append(details, "required")
+	}
+
+	desc := f.Description()
+	if len(details) > 0 {
+		desc = strings.TrimSpace(desc + " (" + strings.Join(details, ", ") + ")")
+	}
+
+	return desc
+}
+
+// usageDefault returns the value of the `default` tag, or the current value of the field if it is not zero.
+// Current values of secret fields are not shown. String values are quoted.
+func (f *Field) usageDefault() (string, bool) {
+	val, ok := f.DefaultValue()
+	if !ok {
+		if _, secret := f.SecretKey(); secret || f.value.IsZero() {
+			return "", false
+		}
+
+		var err error
+		if val, err = f.ExportValue(); err != nil {
+			return "", false
+		}
+	}
+
+	if f.value.Kind() == reflect.String {
+		return fmt.Sprintf("%q", val), true
+	}
+
+	return val, true
+}
+
+// helpRequested returns true if args contain -h, -help or --help, and none of the fields declare that flag.
+func helpRequested(fields []Field, args []string) bool {
+	declared := make(map[string]bool)
+	for _, field := range fields {
+		if flagName, flag := field.FlagName(); flag {
+			declared[flagName] = true
+		}
+	}
+
+	parsed := parseArgs(args, flagKinds(fields))
+	for _, helpFlag := range helpFlags {
+		if !declared[helpFlag] && parsed.Count(helpFlag) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
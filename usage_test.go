@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+)
+
+type usageTestConfig struct {
+	Host    string `flag:"--host" env:"HOST" default:"localhost" usage:"host to listen on"`
+	Verbose bool   `flag:"-v" desc:"enable verbose logs"`
+	Count   int    `flag:"--count"`
+	DB      struct {
+		Name string `env:"DB_NAME" usage:"database name"`
+		Pass string `flag:"--db-pass" env:"DB_PASS" secret:"db-pass,required"`
+	}
+}
+
+func TestUsage(t *testing.T) {
+	cfg := usageTestConfig{Count: 3}
+
+	got := Usage(&cfg)
+	want := `Flags:
+  --host string      host to listen on (default "localhost", env HOST)
+  -v                 enable verbose logs
+  --count int        (default 3)
+  --db-pass string   (env DB_PASS, secret db-pass, required)
+
+Environment variables:
+  DB_NAME string   database name
+`
+
+	if got != want {
+		t.Fatalf("got != want: got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestLoad_help(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load[usageTestConfig](LoadCfg{Flags: true, Args: []string{"--host=example.com", "--help"}})
+	if !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got: %v", err)
+	}
+
+	var helpErr *HelpError
+	if !errors.As(err, &helpErr) || helpErr.Usage != Usage(&usageTestConfig{}) {
+		t.Fatalf("expected *HelpError with usage, got: %v", err)
+	}
+}
+
+func TestLoadFlags_helpDeclared(t *testing.T) {
+	type Config struct {
+		Host string `flag:"-h"`
+	}
+
+	t.Parallel()
+
+	var cfg Config
+	if err := LoadFlagsFrom(&cfg, []string{"-h", "localhost"}); err != nil {
+		t.Fatalf("LoadFlags: %v", err)
+	}
+
+	if cfg.Host != "localhost" {
+		t.Fatalf("cfg.Host = %q, want %q", cfg.Host, "localhost")
+	}
+}
@@ -0,0 +1,274 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	minTag      = "min"
+	maxTag      = "max"
+	regexTag    = "regex"
+	nonemptyTag = "nonempty"
+)
+
+// Validator is implemented by configs and nested structs that validate themselves, see Validate.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is returned when fields fail validation.
+// It lists every violation, so that all of them can be fixed in one go.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Violation describes a field that failed validation, along with the source that supplied its value (if known).
+type Violation struct {
+	Path   string // eg: "DB.Port", or "" for the Validate method of the config itself
+	Source string // eg: "env DB_PORT"
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid configuration:")
+
+	for _, v := range e.Violations {
+		sb.WriteString("\n  ")
+		if v.Path != "" {
+			sb.WriteString(v.Path + ": ")
+		}
+		sb.WriteString(v.Err.Error())
+
+		if v.Source != "" {
+			sb.WriteString(" (from " + v.Source + ")")
+		}
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the errors of the violations, so that errors.Is and errors.As match errors returned by Validate methods.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		errs = append(errs, v.Err)
+	}
+
+	return errs
+}
+
+// Validate checks the fields of ptr against their validation tags, then calls the Validate method
+// of every nested struct and of ptr itself, if they implement Validator.
+// Load calls Validate after loading all sources, in which case violations include the source of the bad value.
+// The following tags are supported:
+//   - `nonempty:"true"`: the value must not be zero, or for strings, slices and maps, must not be empty
+//   - `min:"1"` and `max:"65535"`: bounds of numbers (including time.Duration, eg: `min:"1s"`),
+//     or of the length of strings, slices and maps
+//   - `oneof:"debug info warn"`: the value must be one of the space separated values
+//   - `regex:"^https?://"`: the value must match the regular expression
+//
+// oneof and regex are not checked for zero values, use nonempty to require them.
+// All violations are returned in a *ValidationError. The values of secret fields are not included in violations.
+func Validate(ptr any) error {
+	fields, err := FlattenStructFields(ptr)
+	if err != nil {
+		return err
+	}
+
+	return validate(ptr, fields, make([][]Origin, len(fields)))
+}
+
+// validate checks fields and calls Validate methods, using origins to name the source of bad values.
+func validate(ptr any, fields []Field, origins [][]Origin) error {
+	var violations []Violation
+	for i := range fields {
+		var source string
+		if len(origins[i]) > 0 {
+			source = origins[i][len(origins[i])-1].String()
+		}
+
+		for _, err := range fields[i].validateTags() {
+			violations = append(violations, Violation{Path: fields[i].Path(), Source: source, Err: err})
+		}
+	}
+
+	for i := range fields {
+		if v, ok := fields[i].validator(); ok {
+			if err := v.Validate(); err != nil {
+				violations = append(violations, Violation{Path: fields[i].Path(), Err: err})
+			}
+		}
+	}
+
+	if v, ok := ptr.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			violations = append(violations, Violation{Err: err})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: violations}
+}
+
+// validator returns the field as a Validator, if its type or pointer type implements it.
+func (f *Field) validator() (Validator, bool) {
+	if f.value.Kind() == reflect.Ptr && f.value.IsNil() {
+		return nil, false
+	}
+
+	if v, ok := f.value.Interface().(Validator); ok {
+		return v, true
+	}
+
+	if f.value.CanAddr() {
+		v, ok := f.value.Addr().Interface().(Validator)
+		return v, ok
+	}
+
+	return nil, false
+}
+
+// validateTags returns an error for every validation tag of the field that the value violates.
+func (f *Field) validateTags() []error {
+	var errs []error
+	if nonempty, err := strconv.ParseBool(f.field.Tag.Get(nonemptyTag)); err == nil && nonempty && f.isEmpty() {
+		errs = append(errs, errors.New("must not be empty"))
+	}
+
+	if minVal := f.field.Tag.Get(minTag); minVal != "" {
+		if err := f.checkBound(minVal, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if maxVal := f.field.Tag.Get(maxTag); maxVal != "" {
+		if err := f.checkBound(maxVal, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if oneof := f.OneOf(); len(oneof) > 0 && !f.value.IsZero() {
+		val, err := f.ExportValue()
+		if err != nil {
+			errs = append(errs, err)
+		} else if !contains(oneof, val) {
+			errs = append(errs, fmt.Errorf("must be one of %s%s", strings.Join(oneof, ", "), f.gotValue(strconv.Quote(val))))
+		}
+	}
+
+	if expr := f.field.Tag.Get(regexTag); expr != "" && !f.value.IsZero() {
+		if err := f.checkRegex(expr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// isEmpty returns true if the value is zero, or for strings, slices and maps, has no elements.
+func (f *Field) isEmpty() bool {
+	switch f.value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return f.value.Len() == 0
+	}
+
+	return f.value.IsZero()
+}
+
+// checkBound checks the value of a number field, or the length of a string, slice or map field, against a min or max tag.
+func (f *Field) checkBound(bound string, isMin bool) error {
+	tag := maxTag
+	if isMin {
+		tag = minTag
+	}
+
+	var val, limit float64
+	switch f.value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q: %w", tag, bound, err)
+		}
+
+		if isMin && f.value.Len() < n {
+			return fmt.Errorf("length must be at least %d, got %d", n, f.value.Len())
+		}
+		if !isMin && f.value.Len() > n {
+			return fmt.Errorf("length must be at most %d, got %d", n, f.value.Len())
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// decode the bound like the field, eg: "1s" for a time.Duration
+		tmp := tempField(f.field.Type, f.field.Tag)
+		if err := tmp.setString(bound, true); err != nil {
+			return fmt.Errorf("invalid %s tag %q: %w", tag, bound, err)
+		}
+		val, limit = toFloat(f.value), toFloat(tmp.value)
+
+	default:
+		return fmt.Errorf("%s tag is not supported for type %s", tag, f.field.Type)
+	}
+
+	if (isMin && val >= limit) || (!isMin && val <= limit) {
+		return nil
+	}
+
+	got, _ := f.ExportValue()
+	if isMin {
+		return fmt.Errorf("must be at least %s%s", bound, f.gotValue(got))
+	}
+	return fmt.Errorf("must be at most %s%s", bound, f.gotValue(got))
+}
+
+// toFloat returns a number value as a float64.
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+
+	return v.Float()
+}
+
+// checkRegex checks that the value of the field, as exported by ExportValue, matches expr.
+func (f *Field) checkRegex(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid %s tag %q: %w", regexTag, expr, err)
+	}
+
+	val, err := f.ExportValue()
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(val) {
+		return fmt.Errorf("must match %q%s", expr, f.gotValue(strconv.Quote(val)))
+	}
+
+	return nil
+}
+
+// gotValue returns the value for violation messages, eg: `, got "x"`,
+// or "" for secret fields, so that secrets are not leaked into logs (like Print masks them).
+func (f *Field) gotValue(val string) string {
+	if _, secret := f.SecretKey(); secret {
+		return ""
+	}
+
+	return ", got " + val
+}
@@ -0,0 +1,113 @@
+package conf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errNoScheme = errors.New("url must have a scheme")
+
+type validateTestDB struct {
+	URL string `env:"DB_URL"`
+}
+
+func (db validateTestDB) Validate() error {
+	if db.URL != "" && db.URL[:4] != "post" {
+		return errNoScheme
+	}
+	return nil
+}
+
+type validateTestConfig struct {
+	Host    string        `env:"HOST" nonempty:"true"`
+	Port    int           `env:"PORT" flag:"--port" default:"8080" min:"1" max:"65535"`
+	Level   string        `env:"LEVEL" oneof:"debug info warn"`
+	Name    string        `env:"NAME" regex:"^[a-z]+$" max:"8"`
+	Tags    []string      `env:"TAGS" min:"1"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s" min:"1s"`
+	DB      validateTestDB
+}
+
+func (c *validateTestConfig) Validate() error {
+	if c.Level == "debug" && c.Port == 80 {
+		return errors.New("debug is not allowed on port 80")
+	}
+	return nil
+}
+
+func TestLoad_validate(t *testing.T) {
+	env := EnvMap{"HOST": "localhost", "LEVEL": "info", "NAME": "api", "TAGS": "a"}
+	if _, err := Load[validateTestConfig](LoadCfg{Env: true, EnvLookup: env}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	env = EnvMap{"PORT": "70000", "LEVEL": "trace", "NAME": "API-server", "TIMEOUT": "10ms", "DB_URL": "mysql://"}
+	_, err := Load[validateTestConfig](LoadCfg{Env: true, Flags: true, EnvLookup: env, Args: []string{"--port", "0"}})
+
+	want := `invalid configuration:
+  Host: must not be empty
+  Port: must be at least 1, got 0 (from flag --port)
+  Level: must be one of debug, info, warn, got "trace" (from env LEVEL)
+  Name: length must be at most 8, got 10 (from env NAME)
+  Name: must match "^[a-z]+$", got "API-server" (from env NAME)
+  Tags: length must be at least 1, got 0
+  Timeout: must be at least 1s, got 10ms (from env TIMEOUT)
+  DB: url must have a scheme`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got != want:\n%v\n!=\n%s", err, want)
+	}
+	if !errors.Is(err, errNoScheme) {
+		t.Fatalf("expected errors.Is to match the error of the Validate method")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := validateTestConfig{Host: "localhost", Port: 80, Level: "debug", Tags: []string{"a"}, Timeout: time.Second}
+
+	err := Validate(&cfg)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 1 {
+		t.Fatalf("expected a *ValidationError with 1 violation, got: %v", err)
+	}
+	if got := validationErr.Violations[0]; got.Path != "" || got.Err.Error() != "debug is not allowed on port 80" {
+		t.Fatalf("unexpected violation: %+v", got)
+	}
+}
+
+func TestValidate_invalidTag(t *testing.T) {
+	var cfg struct {
+		Port int  `min:"one"`
+		Ok   bool `max:"1"`
+	}
+
+	want := `invalid configuration:
+  Port: invalid min tag "one": invalid character 'o' looking for beginning of value, raw value: "one"
+  Ok: max tag is not supported for type bool`
+	if err := Validate(&cfg); err == nil || err.Error() != want {
+		t.Fatalf("got != want:\n%v\n!=\n%s", err, want)
+	}
+}
+
+func TestLoad_validateSecret(t *testing.T) {
+	type Config struct {
+		Pass  string `secret:"p" regex:"^[A-Z]"`
+		Level string `secret:"l" oneof:"debug info"`
+		Port  int    `secret:"port" max:"10"`
+	}
+
+	secrets := mapSecrets{"p": "hunter2", "l": "hunter3", "port": "12345"}
+	_, err := Load[Config](LoadCfg{SecretsLoader: secrets})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 3 {
+		t.Fatalf("expected 3 violations, got: %v", err)
+	}
+	for _, val := range secrets {
+		if strings.Contains(err.Error(), val) {
+			t.Fatalf("secret value %q leaked into the error:\n%v", val, err)
+		}
+	}
+}
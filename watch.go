@@ -0,0 +1,198 @@
+package conf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Change describes a reloaded config, see Watch.
+type Change[T any] struct {
+	Old, New *T
+
+	// Fields are the paths of the fields that changed, eg: []string{"DB.Pass"}.
+	Fields []string
+}
+
+// Watcher holds the latest config loaded by Watch.
+type Watcher[T any] struct {
+	cfg     LoadCfg
+	current atomic.Pointer[T]
+
+	reloadMu sync.Mutex // serializes reloads
+
+	mu          sync.Mutex // guards the fields below
+	subscribers map[int]func(Change[T])
+	nextID      int
+	err         error
+}
+
+// Watch loads T like Load, then reloads it every interval until ctx is done,
+// eg: to pick up rotated secrets or edited config files without a restart.
+// When a reload changes any field, subscribers are notified with the old and new config and the paths of the changed fields.
+// If a reload fails, the previous config is kept and the error is returned by Err.
+// An error is returned if interval is not positive, or if the initial load fails.
+// cfg.Provenance and cfg.Command are only set by the initial load.
+// cfg.Context, if not nil, only limits the initial load, eg: with context.WithTimeout. Reloads load sources with ctx.
+// Eg:
+//
+//	w, err := conf.Watch[Config](ctx, conf.LoadCfg{Env: true, SecretsLoader: sm}, time.Minute)
+//	if err != nil {
+//		panic(err)
+//	}
+//	w.Subscribe(func(c conf.Change[Config]) {
+//		log.Println("config changed:", c.Fields)
+//	})
+//
+//	cfg := w.Get() // in request handlers
+func Watch[T any](ctx context.Context, cfg LoadCfg, interval time.Duration) (*Watcher[T], error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+
+	if cfg.Context == nil {
+		cfg.Context = ctx
+	}
+
+	v, err := Load[T](cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Provenance = nil
+	cfg.Command = nil
+	// a timeout of cfg.Context would fail every reload once it expired
+	cfg.Context = ctx
+
+	w := &Watcher[T]{cfg: cfg, subscribers: make(map[int]func(Change[T]))}
+	w.current.Store(&v)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = w.Reload()
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// Get returns the latest config. The config is shared, and must not be modified.
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// Err returns the error of the last reload, or nil if it succeeded.
+func (w *Watcher[T]) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Subscribe registers fn to be called with every change, and returns a function to unsubscribe.
+// Subscribers are called in the goroutine of the reload, one change at a time.
+func (w *Watcher[T]) Subscribe(fn func(Change[T])) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = fn
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		delete(w.subscribers, id)
+	}
+}
+
+// Reload loads the config immediately, eg: on SIGHUP, and notifies subscribers if any field changed.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	old := w.current.Load()
+	v, err := Load[T](w.cfg)
+
+	var changed []string
+	if err == nil {
+		changed, err = diffFields(old, &v)
+	}
+
+	w.mu.Lock()
+	w.err = err
+	var subscribers []func(Change[T])
+	for id := 0; id < w.nextID; id++ {
+		if fn, ok := w.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	w.mu.Unlock()
+
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+
+	w.current.Store(&v)
+
+	change := Change[T]{Old: old, New: &v, Fields: changed}
+	for _, fn := range subscribers {
+		fn(change)
+	}
+
+	return nil
+}
+
+// diffFields returns the paths of the fields whose values differ between a and b.
+// Nested structs are compared field by field.
+func diffFields(a, b any) ([]string, error) {
+	aFields, err := FlattenStructFields(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bFields, err := FlattenStructFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for i := range aFields {
+		field := &aFields[i]
+		if field.value.Kind() == reflect.Struct && !field.isTagged() && !field.isText() {
+			continue
+		}
+
+		if !equalValues(field, &bFields[i]) {
+			changed = append(changed, field.Path())
+		}
+	}
+
+	return changed, nil
+}
+
+// equalValues returns true if the values of a and b are equal.
+// Types decoded from text are compared by their text, eg: *regexp.Regexp.
+func equalValues(a, b *Field) bool {
+	if a.isText() {
+		aVal, aErr := a.ExportValue()
+		bVal, bErr := b.ExportValue()
+		if aErr == nil && bErr == nil {
+			return aVal == bVal
+		}
+	}
+
+	return reflect.DeepEqual(a.value.Interface(), b.value.Interface())
+}
@@ -0,0 +1,156 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rotatingSecrets is a SecretsLoader whose secrets can be changed concurrently.
+type rotatingSecrets struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	err     error
+}
+
+func (s *rotatingSecrets) Load(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.secrets[key]
+	return []byte(val), ok, s.err
+}
+
+func (s *rotatingSecrets) set(key, val string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets[key] = val
+	s.err = err
+}
+
+type watchTestConfig struct {
+	Host string `env:"HOST"`
+	DB   struct {
+		User string `secret:"db-user"`
+		Pass string `secret:"db-pass"`
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	secrets := &rotatingSecrets{secrets: map[string]string{"db-user": "admin", "db-pass": "1"}}
+	cfg := LoadCfg{Env: true, EnvLookup: EnvMap{"HOST": "localhost"}, SecretsLoader: secrets}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := Watch[watchTestConfig](ctx, cfg, time.Hour)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	initial := w.Get()
+
+	var changes []Change[watchTestConfig]
+	w.Subscribe(func(c Change[watchTestConfig]) {
+		changes = append(changes, c)
+	})
+
+	// no change
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(changes) != 0 || w.Get() != initial {
+		t.Fatalf("expected no change, got: %+v", changes)
+	}
+
+	// rotated secret
+	secrets.set("db-pass", "2", nil)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0].Fields, []string{"DB.Pass"}) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if changes[0].Old != initial || changes[0].New != w.Get() || w.Get().DB.Pass != "2" || initial.DB.Pass != "1" {
+		t.Fatalf("unexpected snapshots: old %+v, new %+v", changes[0].Old, changes[0].New)
+	}
+
+	// failed reload keeps the previous config
+	errUnavailable := errors.New("unavailable")
+	secrets.set("db-pass", "3", errUnavailable)
+	if err := w.Reload(); !errors.Is(err, errUnavailable) || !errors.Is(w.Err(), errUnavailable) {
+		t.Fatalf("expected the reload error, got: %v", err)
+	}
+	if len(changes) != 1 || w.Get().DB.Pass != "2" {
+		t.Fatalf("expected the previous config to be kept, got: %+v", w.Get())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	secrets := &rotatingSecrets{secrets: map[string]string{"db-pass": "1"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := Watch[watchTestConfig](ctx, LoadCfg{SecretsLoader: secrets}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	changes := make(chan Change[watchTestConfig], 1)
+	unsubscribe := w.Subscribe(func(c Change[watchTestConfig]) {
+		changes <- c
+	})
+	defer unsubscribe()
+
+	secrets.set("db-pass", "2", nil)
+	select {
+	case c := <-changes:
+		if c.New.DB.Pass != "2" {
+			t.Fatalf("unexpected change: %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a change")
+	}
+}
+
+func TestWatch_initialError(t *testing.T) {
+	secrets := &rotatingSecrets{secrets: map[string]string{}, err: errors.New("unavailable")}
+
+	if _, err := Watch[watchTestConfig](context.Background(), LoadCfg{SecretsLoader: secrets}, time.Hour); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestWatch_invalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := Watch[watchTestConfig](context.Background(), LoadCfg{}, interval); err == nil {
+			t.Fatalf("expected an error for interval %s", interval)
+		}
+	}
+}
+
+func TestWatcher_ReloadAfterLoadTimeout(t *testing.T) {
+	secrets := &rotatingSecrets{secrets: map[string]string{"db-user": "admin", "db-pass": "1"}}
+
+	loadCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	w, err := Watch[watchTestConfig](context.Background(), LoadCfg{Context: loadCtx, SecretsLoader: secrets}, time.Hour)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	// reloads are not limited by the context of the initial load
+	cancel()
+	secrets.set("db-pass", "2", nil)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if w.Get().DB.Pass != "2" {
+		t.Fatalf("expected the rotated secret, got: %+v", w.Get())
+	}
+}